@@ -130,6 +130,10 @@ func (c *Config) ReadConfig() error {
 		return err
 	}
 
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
 	c.logger.Info("Configuration", zap.Any("config", c))
 
 	return nil
@@ -141,18 +145,47 @@ func (c *Config) SetDefault() error {
 	c.Server.Address = "0.0.0.0"
 	c.Server.Port = 8080
 	c.Server.IdleTimeout = time.Second * 5 //nolint:gomnd
+	c.Server.DegradedStatusCode = 200
+	c.GRPC.Enabled = false
+	c.GRPC.Address = "0.0.0.0"
+	c.GRPC.Port = 9090
+	c.GRPC.WatchInterval = time.Second * 5 //nolint:gomnd
+	c.Metrics.Enabled = true
+	c.Metrics.Path = "/metrics"
+	c.Scheduler.Enabled = false
+	c.Scheduler.Interval = time.Second * 10   //nolint:gomnd
+	c.Scheduler.IdleTimeout = time.Minute * 5 //nolint:gomnd
+	c.Scheduler.MaxJobs = 1000                //nolint:gomnd
 	c.Concurrency = 5
 	c.Kubernetes.Enabled = true
+	c.Kubernetes.MinReady = "1"
+	c.Kubernetes.EndpointSlices = true
+	c.Kubernetes.Condition = "ready"
+	c.Kubernetes.Informers = false
+	c.Kubernetes.SyncTimeout = time.Minute
+	c.Kubernetes.NodeLocal = false
+	c.Kubernetes.ProbeTimeout = time.Second * 2 //nolint:gomnd
+	c.Kubernetes.BackendPolicy = "all"
 	c.Consul.Enabled = false
 	c.Consul.Address = "127.0.0.1:8500"
 	c.Consul.Scheme = "http"
 	c.Consul.InsecureSkipVerify = false
 	c.Consul.Timeout = time.Second * 2 //nolint:gomnd
+	c.Consul.Consistency = "default"
+	c.Consul.Watch = false
+	c.Consul.WatchWaitTime = time.Minute * 5    //nolint:gomnd
+	c.Consul.WatchIdleTimeout = time.Minute * 5 //nolint:gomnd
+	c.Consul.MinPassing = 1
+	c.Consul.MinPassingPercent = 0
+	c.Consul.WarningAsPassing = false
+	c.Consul.FailoverPolicy = "any"
+	c.Consul.AgentLocal = false
+	c.Consul.ZoneMetaKey = "zone"
 
 	return nil
 }
 
-func (c *Config) SetFromEnv() error { //nolint:cyclop
+func (c *Config) SetFromEnv() error { //nolint:cyclop,funlen
 	c.logger.Debug("reading environment variables and setting values")
 
 	if v := viper.GetString("server_address"); v != "" {
@@ -163,15 +196,143 @@ func (c *Config) SetFromEnv() error { //nolint:cyclop
 		c.Server.Port = v
 	}
 
+	if v := viper.GetInt("server_degraded_status_code"); v != 0 {
+		c.Server.DegradedStatusCode = v
+	}
+
+	_, ok := os.LookupEnv("HG_SERVER_VERBOSE")
+	if v := viper.GetBool("server_verbose"); ok {
+		c.Server.Verbose = v
+	}
+
+	_, ok = os.LookupEnv("HG_GRPC_ENABLED")
+	if v := viper.GetBool("grpc_enabled"); ok {
+		c.GRPC.Enabled = v
+	}
+
+	if v := viper.GetString("grpc_address"); v != "" {
+		c.GRPC.Address = v
+	}
+
+	if v := viper.GetInt("grpc_port"); v != 0 {
+		c.GRPC.Port = v
+	}
+
+	_, ok = os.LookupEnv("HG_METRICS_ENABLED")
+	if v := viper.GetBool("metrics_enabled"); ok {
+		c.Metrics.Enabled = v
+	}
+
+	if v := viper.GetString("metrics_path"); v != "" {
+		c.Metrics.Path = v
+	}
+
+	_, ok = os.LookupEnv("HG_SCHEDULER_ENABLED")
+	if v := viper.GetBool("scheduler_enabled"); ok {
+		c.Scheduler.Enabled = v
+	}
+
+	if v := viper.GetDuration("scheduler_interval"); v != 0 {
+		c.Scheduler.Interval = v
+	}
+
+	if v := viper.GetDuration("scheduler_idle_timeout"); v != 0 {
+		c.Scheduler.IdleTimeout = v
+	}
+
+	if v := viper.GetInt("scheduler_max_jobs"); v != 0 {
+		c.Scheduler.MaxJobs = v
+	}
+
 	if v := viper.GetInt("concurrency"); v != 0 {
 		c.Concurrency = v
 	}
 
-	_, ok := os.LookupEnv("HG_KUBERNETES_ENABLED")
+	_, ok = os.LookupEnv("HG_KUBERNETES_ENABLED")
 	if v := viper.GetBool("kubernetes_enabled"); ok {
 		c.Kubernetes.Enabled = v
 	}
 
+	if v := viper.GetString("kubernetes_min_ready"); v != "" {
+		c.Kubernetes.MinReady = v
+	}
+
+	_, ok = os.LookupEnv("HG_KUBERNETES_ENDPOINT_SLICES")
+	if v := viper.GetBool("kubernetes_endpoint_slices"); ok {
+		c.Kubernetes.EndpointSlices = v
+	}
+
+	if v := viper.GetString("kubernetes_address_type"); v != "" {
+		c.Kubernetes.AddressType = v
+	}
+
+	if v := viper.GetString("kubernetes_condition"); v != "" {
+		c.Kubernetes.Condition = v
+	}
+
+	_, ok = os.LookupEnv("HG_KUBERNETES_INFORMERS")
+	if v := viper.GetBool("kubernetes_informers"); ok {
+		c.Kubernetes.Informers = v
+	}
+
+	if v := viper.GetString("kubernetes_namespaces"); v != "" {
+		c.Kubernetes.Namespaces = strings.Split(v, ",")
+	}
+
+	if v := viper.GetString("kubernetes_label_selector"); v != "" {
+		c.Kubernetes.LabelSelector = v
+	}
+
+	if v := viper.GetDuration("kubernetes_sync_timeout"); v != 0 {
+		c.Kubernetes.SyncTimeout = v
+	}
+
+	_, ok = os.LookupEnv("HG_KUBERNETES_NODE_LOCAL")
+	if v := viper.GetBool("kubernetes_node_local"); ok {
+		c.Kubernetes.NodeLocal = v
+	}
+
+	if v := viper.GetString("kubernetes_node_name"); v != "" {
+		c.Kubernetes.NodeName = v
+	}
+
+	if v := viper.GetString("kubernetes_min_updated"); v != "" {
+		c.Kubernetes.MinUpdated = v
+	}
+
+	if v := viper.GetString("kubernetes_probe"); v != "" {
+		c.Kubernetes.Probe = v
+	}
+
+	if v := viper.GetInt("kubernetes_probe_port"); v != 0 {
+		c.Kubernetes.ProbePort = v
+	}
+
+	if v := viper.GetString("kubernetes_probe_path"); v != "" {
+		c.Kubernetes.ProbePath = v
+	}
+
+	if v := viper.GetDuration("kubernetes_probe_timeout"); v != 0 {
+		c.Kubernetes.ProbeTimeout = v
+	}
+
+	_, ok = os.LookupEnv("HG_KUBERNETES_PROBE_INSECURE_SKIP_VERIFY")
+	if v := viper.GetBool("kubernetes_probe_insecure_skip_verify"); ok {
+		c.Kubernetes.ProbeInsecureSkipVerify = v
+	}
+
+	if v := viper.GetString("kubernetes_backend_policy"); v != "" {
+		c.Kubernetes.BackendPolicy = v
+	}
+
+	if v := viper.GetInt("kubernetes_rise"); v != 0 {
+		c.Kubernetes.Rise = v
+	}
+
+	if v := viper.GetInt("kubernetes_fall"); v != 0 {
+		c.Kubernetes.Fall = v
+	}
+
 	_, ok = os.LookupEnv("HG_CONSUL_ENABLED")
 	if v := viper.GetBool("consul_enabled"); ok {
 		c.Consul.Enabled = v
@@ -210,6 +371,93 @@ func (c *Config) SetFromEnv() error { //nolint:cyclop
 		c.Consul.InsecureSkipVerify = v
 	}
 
+	if v := viper.GetString("consul_consistency"); v != "" {
+		c.Consul.Consistency = v
+	}
+
+	_, ok = os.LookupEnv("HG_CONSUL_WATCH")
+	if v := viper.GetBool("consul_watch"); ok {
+		c.Consul.Watch = v
+	}
+
+	if v := viper.GetDuration("consul_watch_wait_time"); v != 0 {
+		c.Consul.WatchWaitTime = v
+	}
+
+	if v := viper.GetDuration("consul_watch_idle_timeout"); v != 0 {
+		c.Consul.WatchIdleTimeout = v
+	}
+
+	if v := viper.GetInt("consul_min_passing"); v != 0 {
+		c.Consul.MinPassing = v
+	}
+
+	if v := viper.GetFloat64("consul_min_passing_percent"); v != 0 {
+		c.Consul.MinPassingPercent = v
+	}
+
+	_, ok = os.LookupEnv("HG_CONSUL_WARNING_AS_PASSING")
+	if v := viper.GetBool("consul_warning_as_passing"); ok {
+		c.Consul.WarningAsPassing = v
+	}
+
+	if v := viper.GetString("consul_failover_datacenters"); v != "" {
+		c.Consul.FailoverDatacenters = strings.Split(v, ",")
+	}
+
+	if v := viper.GetString("consul_failover_policy"); v != "" {
+		c.Consul.FailoverPolicy = v
+	}
+
+	_, ok = os.LookupEnv("HG_CONSUL_AGENT_LOCAL")
+	if v := viper.GetBool("consul_agent_local"); ok {
+		c.Consul.AgentLocal = v
+	}
+
+	if v := viper.GetString("consul_zone_meta_key"); v != "" {
+		c.Consul.ZoneMetaKey = v
+	}
+
+	if v := viper.GetInt("consul_rise"); v != 0 {
+		c.Consul.Rise = v
+	}
+
+	if v := viper.GetInt("consul_fall"); v != 0 {
+		c.Consul.Fall = v
+	}
+
+	return nil
+}
+
+// Validate returns an error if the configuration has values that can't be used.
+func (c *Config) Validate() error {
+	if c.Scheduler.Enabled && c.Scheduler.Interval <= 0 {
+		return xerrors.Errorf("scheduler interval has to be greater than zero, interval: %s", c.Scheduler.Interval)
+	}
+
+	if c.Scheduler.MaxJobs < 0 {
+		return xerrors.Errorf("maximum number of scheduler jobs can't be negative, maxJobs: %d", c.Scheduler.MaxJobs)
+	}
+
+	// Checks without an interval use the scheduler interval.
+	for i, check := range c.HTTPHealthCheck {
+		if check.Interval < 0 {
+			return xerrors.Errorf("interval of HTTP check can't be negative, check: %d, interval: %s", i, check.Interval)
+		}
+	}
+
+	for i, check := range c.TCPHealthCheck {
+		if check.Interval < 0 {
+			return xerrors.Errorf("interval of TCP check can't be negative, check: %d, interval: %s", i, check.Interval)
+		}
+	}
+
+	for i, check := range c.GRPCHealthCheck {
+		if check.Interval < 0 {
+			return xerrors.Errorf("interval of gRPC check can't be negative, check: %d, interval: %s", i, check.Interval)
+		}
+	}
+
 	return nil
 }
 
@@ -42,6 +42,7 @@ func TestReadConfigMap(t *testing.T) {
 	assert.Equal(t, 8080, config.Server.Port)
 	assert.Equal(t, 5, config.Concurrency)
 	assert.Equal(t, HTTPHealthCheck{
+		Name:        "consul",
 		Type:        "http",
 		Host:        "127.0.0.1",
 		RequestPath: "/test",
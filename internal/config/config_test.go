@@ -20,6 +20,17 @@ func TestSetFromEnv(t *testing.T) {
 	os.Setenv("HG_SERVER_ADDRESS", "testhost")
 	os.Setenv("HG_CONCURRENCY", "1")
 	os.Setenv("HG_SERVER_PORT", "123")
+	os.Setenv("HG_SERVER_VERBOSE", "true")
+	os.Setenv("HG_SERVER_DEGRADED_STATUS_CODE", "207")
+	os.Setenv("HG_GRPC_ENABLED", "true")
+	os.Setenv("HG_GRPC_ADDRESS", "127.0.0.1")
+	os.Setenv("HG_GRPC_PORT", "9091")
+	os.Setenv("HG_METRICS_ENABLED", "false")
+	os.Setenv("HG_METRICS_PATH", "/prometheus")
+	os.Setenv("HG_SCHEDULER_ENABLED", "true")
+	os.Setenv("HG_SCHEDULER_INTERVAL", "30s")
+	os.Setenv("HG_SCHEDULER_IDLE_TIMEOUT", "1h")
+	os.Setenv("HG_SCHEDULER_MAX_JOBS", "500")
 	os.Setenv("HG_KUBERNETES_ENABLED", "true")
 	os.Setenv("HG_CONSUL_ENABLED", "true")
 	os.Setenv("HG_CONSUL_ADDRESS", "consul.host:8500")
@@ -30,12 +41,55 @@ func TestSetFromEnv(t *testing.T) {
 	os.Setenv("HG_CONSUL_CERT_FILE", "/certfile")
 	os.Setenv("HG_CONSUL_KEY_FILE", "/keyfile")
 	os.Setenv("HG_CONSUL_TIMEOUT", "10s")
+	os.Setenv("HG_KUBERNETES_RISE", "2")
+	os.Setenv("HG_KUBERNETES_MIN_READY", "50%")
+	os.Setenv("HG_KUBERNETES_ENDPOINT_SLICES", "false")
+	os.Setenv("HG_KUBERNETES_ADDRESS_TYPE", "IPv6")
+	os.Setenv("HG_KUBERNETES_CONDITION", "serving")
+	os.Setenv("HG_KUBERNETES_INFORMERS", "true")
+	os.Setenv("HG_KUBERNETES_NAMESPACES", "default,kube-system")
+	os.Setenv("HG_KUBERNETES_NODE_LOCAL", "true")
+	os.Setenv("HG_KUBERNETES_MIN_UPDATED", "50%")
+	os.Setenv("HG_KUBERNETES_NODE_NAME", "node-a")
+	os.Setenv("HG_KUBERNETES_PROBE", "http")
+	os.Setenv("HG_KUBERNETES_BACKEND_POLICY", "quorum")
+	os.Setenv("HG_KUBERNETES_PROBE_PORT", "8080")
+	os.Setenv("HG_KUBERNETES_PROBE_PATH", "/healthz")
+	os.Setenv("HG_KUBERNETES_PROBE_TIMEOUT", "5s")
+	os.Setenv("HG_KUBERNETES_PROBE_INSECURE_SKIP_VERIFY", "true")
+	os.Setenv("HG_CONSUL_AGENT_LOCAL", "true")
+	os.Setenv("HG_CONSUL_ZONE_META_KEY", "availability-zone")
+	os.Setenv("HG_KUBERNETES_LABEL_SELECTOR", "healthgroup=enabled")
+	os.Setenv("HG_KUBERNETES_SYNC_TIMEOUT", "30s")
+	os.Setenv("HG_KUBERNETES_FALL", "3")
+	os.Setenv("HG_CONSUL_RISE", "4")
+	os.Setenv("HG_CONSUL_CONSISTENCY", "stale")
+	os.Setenv("HG_CONSUL_MIN_PASSING", "2")
+	os.Setenv("HG_CONSUL_MIN_PASSING_PERCENT", "50")
+	os.Setenv("HG_CONSUL_WARNING_AS_PASSING", "true")
+	os.Setenv("HG_CONSUL_WATCH", "true")
+	os.Setenv("HG_CONSUL_FAILOVER_DATACENTERS", "dc2,dc3")
+	os.Setenv("HG_CONSUL_FAILOVER_POLICY", "primary")
+	os.Setenv("HG_CONSUL_WATCH_WAIT_TIME", "1m")
+	os.Setenv("HG_CONSUL_WATCH_IDLE_TIMEOUT", "10m")
+	os.Setenv("HG_CONSUL_FALL", "5")
 
 	err := config.SetFromEnv()
 
 	assert.Equal(t, "testhost", config.Server.Address, "HG_SERVER_ADDRESS - should be equal")
 	assert.Equal(t, 1, config.Concurrency, "HG_CONCURRENCY - should be equal")
 	assert.Equal(t, 123, config.Server.Port, "HG_SERVER_PORT - should be equal")
+	assert.Equal(t, true, config.Server.Verbose, "HG_SERVER_VERBOSE - should be equal")
+	assert.Equal(t, 207, config.Server.DegradedStatusCode, "HG_SERVER_DEGRADED_STATUS_CODE - should be equal")
+	assert.Equal(t, true, config.GRPC.Enabled, "HG_GRPC_ENABLED - should be equal")
+	assert.Equal(t, "127.0.0.1", config.GRPC.Address, "HG_GRPC_ADDRESS - should be equal")
+	assert.Equal(t, 9091, config.GRPC.Port, "HG_GRPC_PORT - should be equal")
+	assert.Equal(t, false, config.Metrics.Enabled, "HG_METRICS_ENABLED - should be equal")
+	assert.Equal(t, "/prometheus", config.Metrics.Path, "HG_METRICS_PATH - should be equal")
+	assert.Equal(t, true, config.Scheduler.Enabled, "HG_SCHEDULER_ENABLED - should be equal")
+	assert.Equal(t, time.Second*30, config.Scheduler.Interval, "HG_SCHEDULER_INTERVAL - should be equal")
+	assert.Equal(t, time.Hour, config.Scheduler.IdleTimeout, "HG_SCHEDULER_IDLE_TIMEOUT - should be equal")
+	assert.Equal(t, 500, config.Scheduler.MaxJobs, "HG_SCHEDULER_MAX_JOBS - should be equal")
 	assert.Equal(t, true, config.Kubernetes.Enabled, "HG_KUBERNETES_ENABLED - should be equal")
 	assert.Equal(t, true, config.Consul.Enabled, "HG_CONSUL_ENABLED - should be equal")
 	assert.Equal(t, "consul.host:8500", config.Consul.Address, "HG_CONSUL_ADDRESS - should be equal")
@@ -46,6 +100,38 @@ func TestSetFromEnv(t *testing.T) {
 	assert.Equal(t, "/certfile", config.Consul.CertFile, "HG_CONSUL_CERT_FILE - should be equal")
 	assert.Equal(t, "/keyfile", config.Consul.KeyFile, "HG_CONSUL_KEY_FILE - should be equal")
 	assert.Equal(t, time.Second*10, config.Consul.Timeout, "HG_CONSUL_TIMEOUT - should be equal")
+	assert.Equal(t, 2, config.Kubernetes.Rise, "HG_KUBERNETES_RISE - should be equal")
+	assert.Equal(t, "50%", config.Kubernetes.MinReady, "HG_KUBERNETES_MIN_READY - should be equal")
+	assert.Equal(t, false, config.Kubernetes.EndpointSlices, "HG_KUBERNETES_ENDPOINT_SLICES - should be equal")
+	assert.Equal(t, "IPv6", config.Kubernetes.AddressType, "HG_KUBERNETES_ADDRESS_TYPE - should be equal")
+	assert.Equal(t, "serving", config.Kubernetes.Condition, "HG_KUBERNETES_CONDITION - should be equal")
+	assert.Equal(t, true, config.Kubernetes.Informers, "HG_KUBERNETES_INFORMERS - should be equal")
+	assert.Equal(t, []string{"default", "kube-system"}, config.Kubernetes.Namespaces, "HG_KUBERNETES_NAMESPACES - should be equal")
+	assert.Equal(t, true, config.Kubernetes.NodeLocal, "HG_KUBERNETES_NODE_LOCAL - should be equal")
+	assert.Equal(t, "50%", config.Kubernetes.MinUpdated, "HG_KUBERNETES_MIN_UPDATED - should be equal")
+	assert.Equal(t, "node-a", config.Kubernetes.NodeName, "HG_KUBERNETES_NODE_NAME - should be equal")
+	assert.Equal(t, "http", config.Kubernetes.Probe, "HG_KUBERNETES_PROBE - should be equal")
+	assert.Equal(t, "quorum", config.Kubernetes.BackendPolicy, "HG_KUBERNETES_BACKEND_POLICY - should be equal")
+	assert.Equal(t, 8080, config.Kubernetes.ProbePort, "HG_KUBERNETES_PROBE_PORT - should be equal")
+	assert.Equal(t, "/healthz", config.Kubernetes.ProbePath, "HG_KUBERNETES_PROBE_PATH - should be equal")
+	assert.Equal(t, time.Second*5, config.Kubernetes.ProbeTimeout, "HG_KUBERNETES_PROBE_TIMEOUT - should be equal")
+	assert.Equal(t, true, config.Kubernetes.ProbeInsecureSkipVerify, "HG_KUBERNETES_PROBE_INSECURE_SKIP_VERIFY - should be equal")
+	assert.Equal(t, true, config.Consul.AgentLocal, "HG_CONSUL_AGENT_LOCAL - should be equal")
+	assert.Equal(t, "availability-zone", config.Consul.ZoneMetaKey, "HG_CONSUL_ZONE_META_KEY - should be equal")
+	assert.Equal(t, "healthgroup=enabled", config.Kubernetes.LabelSelector, "HG_KUBERNETES_LABEL_SELECTOR - should be equal")
+	assert.Equal(t, time.Second*30, config.Kubernetes.SyncTimeout, "HG_KUBERNETES_SYNC_TIMEOUT - should be equal")
+	assert.Equal(t, 3, config.Kubernetes.Fall, "HG_KUBERNETES_FALL - should be equal")
+	assert.Equal(t, 4, config.Consul.Rise, "HG_CONSUL_RISE - should be equal")
+	assert.Equal(t, "stale", config.Consul.Consistency, "HG_CONSUL_CONSISTENCY - should be equal")
+	assert.Equal(t, 2, config.Consul.MinPassing, "HG_CONSUL_MIN_PASSING - should be equal")
+	assert.Equal(t, float64(50), config.Consul.MinPassingPercent, "HG_CONSUL_MIN_PASSING_PERCENT - should be equal")
+	assert.Equal(t, true, config.Consul.WarningAsPassing, "HG_CONSUL_WARNING_AS_PASSING - should be equal")
+	assert.Equal(t, true, config.Consul.Watch, "HG_CONSUL_WATCH - should be equal")
+	assert.Equal(t, []string{"dc2", "dc3"}, config.Consul.FailoverDatacenters, "HG_CONSUL_FAILOVER_DATACENTERS - should be equal")
+	assert.Equal(t, "primary", config.Consul.FailoverPolicy, "HG_CONSUL_FAILOVER_POLICY - should be equal")
+	assert.Equal(t, time.Minute, config.Consul.WatchWaitTime, "HG_CONSUL_WATCH_WAIT_TIME - should be equal")
+	assert.Equal(t, time.Minute*10, config.Consul.WatchIdleTimeout, "HG_CONSUL_WATCH_IDLE_TIMEOUT - should be equal")
+	assert.Equal(t, 5, config.Consul.Fall, "HG_CONSUL_FALL - should be equal")
 
 	assert.Nil(t, err, "error should be nil")
 }
@@ -60,13 +146,41 @@ func TestSetDefault(t *testing.T) {
 	assert.Equal(t, "0.0.0.0", config.Server.Address)
 	assert.Equal(t, 8080, config.Server.Port)
 	assert.Equal(t, time.Second*5, config.Server.IdleTimeout)
+	assert.Equal(t, false, config.Server.Verbose)
+	assert.Equal(t, 200, config.Server.DegradedStatusCode)
+	assert.Equal(t, false, config.GRPC.Enabled)
+	assert.Equal(t, "0.0.0.0", config.GRPC.Address)
+	assert.Equal(t, 9090, config.GRPC.Port)
+	assert.Equal(t, time.Second*5, config.GRPC.WatchInterval)
+	assert.Equal(t, true, config.Metrics.Enabled)
+	assert.Equal(t, "/metrics", config.Metrics.Path)
+	assert.Equal(t, false, config.Scheduler.Enabled)
+	assert.Equal(t, time.Second*10, config.Scheduler.Interval)
+	assert.Equal(t, time.Minute*5, config.Scheduler.IdleTimeout)
+	assert.Equal(t, 1000, config.Scheduler.MaxJobs)
 	assert.Equal(t, 5, config.Concurrency)
 	assert.Equal(t, true, config.Kubernetes.Enabled)
+	assert.Equal(t, "1", config.Kubernetes.MinReady)
+	assert.Equal(t, true, config.Kubernetes.EndpointSlices)
+	assert.Equal(t, "ready", config.Kubernetes.Condition)
+	assert.Equal(t, false, config.Kubernetes.Informers)
+	assert.Equal(t, time.Minute, config.Kubernetes.SyncTimeout)
+	assert.Equal(t, time.Second*2, config.Kubernetes.ProbeTimeout)
+	assert.Equal(t, "all", config.Kubernetes.BackendPolicy)
 	assert.Equal(t, false, config.Consul.Enabled)
 	assert.Equal(t, "127.0.0.1:8500", config.Consul.Address)
 	assert.Equal(t, "http", config.Consul.Scheme)
 	assert.Empty(t, config.Consul.Token)
 	assert.Equal(t, time.Second*2, config.Consul.Timeout)
+	assert.Equal(t, "default", config.Consul.Consistency)
+	assert.Equal(t, 1, config.Consul.MinPassing)
+	assert.Equal(t, false, config.Consul.WarningAsPassing)
+	assert.Equal(t, false, config.Consul.Watch)
+	assert.Empty(t, config.Consul.FailoverDatacenters)
+	assert.Equal(t, "any", config.Consul.FailoverPolicy)
+	assert.Equal(t, "zone", config.Consul.ZoneMetaKey)
+	assert.Equal(t, time.Minute*5, config.Consul.WatchWaitTime)
+	assert.Equal(t, time.Minute*5, config.Consul.WatchIdleTimeout)
 	assert.Equal(t, false, config.Consul.InsecureSkipVerify)
 	assert.Empty(t, config.Consul.CAFile)
 	assert.Empty(t, config.Consul.CertFile)
@@ -103,8 +217,63 @@ func TestReadConfig(t *testing.T) {
 		Timeout: 5 * time.Second,
 		Service: "test",
 	}, config.HTTPHealthCheck[0])
+	assert.Equal(t, TCPHealthCheck{
+		Timeout: 2 * time.Second,
+		Host:    "127.0.0.1",
+		Port:    6379,
+		Send:    "PING\r\n",
+		Expect:  `^\+PONG`,
+		Service: "redis",
+		Group:   "cache",
+	}, config.TCPHealthCheck[0])
+	assert.Equal(t, []CheckGroup{{Name: "cache", MinPassing: "1"}}, config.CheckGroups)
+	assert.Equal(t, []KubernetesCluster{{
+		Name:   "eu-west",
+		Server: "https://eu-west.example.com:6443",
+		Token:  "secret",
+	}}, config.Kubernetes.Clusters)
 	assert.Equal(t, true, config.Consul.Enabled)
 
 	assert.Nil(t, errDefault, "error should be nil")
 	assert.Nil(t, err, "error should be nil")
 }
+
+func TestValidate(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	config := New(WithLogger(logger))
+	assert.NoError(t, config.SetDefault())
+	assert.NoError(t, config.Validate())
+
+	// The interval isn't used if the scheduler is disabled.
+	config.Scheduler.Interval = 0
+	assert.NoError(t, config.Validate())
+
+	config.Scheduler.Enabled = true
+	assert.Error(t, config.Validate())
+
+	config.Scheduler.Interval = -time.Second
+	assert.Error(t, config.Validate())
+
+	config.Scheduler.Interval = time.Second
+	config.Scheduler.MaxJobs = -1
+	assert.Error(t, config.Validate())
+
+	// Checks without an interval use the scheduler interval.
+	config.Scheduler.MaxJobs = 0
+	config.HTTPHealthCheck = []HTTPHealthCheck{{}}
+	config.TCPHealthCheck = []TCPHealthCheck{{Interval: time.Second}}
+	config.GRPCHealthCheck = []GRPCHealthCheck{{}}
+	assert.NoError(t, config.Validate())
+
+	config.TCPHealthCheck[0].Interval = -5 * time.Second
+	assert.Error(t, config.Validate())
+
+	config.TCPHealthCheck[0].Interval = 0
+	config.HTTPHealthCheck[0].Interval = -time.Second
+	assert.Error(t, config.Validate())
+
+	config.HTTPHealthCheck[0].Interval = 0
+	config.GRPCHealthCheck[0].Interval = -time.Second
+	assert.Error(t, config.Validate())
+}
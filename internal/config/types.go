@@ -20,19 +20,52 @@ type Config struct {
 	file            string
 	flags           *Flags
 	Server          Server
+	GRPC            GRPC
+	Metrics         Metrics
+	Scheduler       Scheduler
 	HTTPHealthCheck []HTTPHealthCheck
+	TCPHealthCheck  []TCPHealthCheck
+	GRPCHealthCheck []GRPCHealthCheck
+	CheckGroups     []CheckGroup
 	Concurrency     int
 	Kubernetes      Kubernetes
 	Consul          Consul
 }
 
 type Server struct {
-	Address     string
-	Port        int
+	Address            string
+	Port               int
+	IdleTimeout        time.Duration
+	Verbose            bool
+	DegradedStatusCode int
+}
+
+type GRPC struct {
+	Enabled       bool
+	Address       string
+	Port          int
+	WatchInterval time.Duration
+}
+
+type Metrics struct {
+	Enabled bool
+	Path    string
+}
+
+type Scheduler struct {
+	Enabled     bool
+	Interval    time.Duration
 	IdleTimeout time.Duration
+	MaxJobs     int
 }
 
 type HTTPHealthCheck struct {
+	Interval           time.Duration
+	Rise               int
+	Fall               int
+	Severity           string
+	Group              string
+	Name               string
 	Timeout            time.Duration
 	Type               string
 	RequestPath        string
@@ -42,20 +75,109 @@ type HTTPHealthCheck struct {
 	Namespace          string
 	InsecureSkipVerify bool
 	Discovery          string
+	Cluster            string
+}
+
+type TCPHealthCheck struct {
+	Interval  time.Duration
+	Rise      int
+	Fall      int
+	Severity  string
+	Group     string
+	Name      string
+	Timeout   time.Duration
+	Host      string
+	Port      int
+	Send      string
+	Expect    string
+	Service   string
+	Namespace string
+	Discovery string
+	Cluster   string
+}
+
+type GRPCHealthCheck struct {
+	Interval           time.Duration
+	Rise               int
+	Fall               int
+	Severity           string
+	Group              string
+	Name               string
+	Timeout            time.Duration
+	Target             string
+	GRPCService        string
+	TLS                bool
+	InsecureSkipVerify bool
+	Service            string
+	Namespace          string
+	Discovery          string
+	Cluster            string
+}
+
+type CheckGroup struct {
+	Name       string
+	MinPassing string
+	Severity   string
 }
 
 type Kubernetes struct {
-	Enabled bool
+	Enabled                 bool
+	MinReady                string
+	MinUpdated              string
+	EndpointSlices          bool
+	AddressType             string
+	Condition               string
+	Informers               bool
+	Namespaces              []string
+	LabelSelector           string
+	SyncTimeout             time.Duration
+	NodeLocal               bool
+	NodeName                string
+	Probe                   string
+	ProbePort               int
+	ProbePath               string
+	ProbeTimeout            time.Duration
+	ProbeInsecureSkipVerify bool
+	Clusters                []KubernetesCluster
+	BackendPolicy           string
+	Rise                    int
+	Fall                    int
+}
+
+// KubernetesCluster is a named Kubernetes cluster. The cluster is reached either from
+// within the cluster, by the API server address and token, or by a kubeconfig context.
+type KubernetesCluster struct {
+	Name       string
+	InCluster  bool
+	Kubeconfig string
+	Context    string
+	Server     string
+	Token      string
+	TokenFile  string
+	CAFile     string
 }
 
 type Consul struct {
-	Enabled            bool
-	Address            string
-	Scheme             string
-	CAFile             string
-	CertFile           string
-	KeyFile            string
-	InsecureSkipVerify bool
-	Token              string
-	Timeout            time.Duration
+	Enabled             bool
+	Address             string
+	Scheme              string
+	CAFile              string
+	CertFile            string
+	KeyFile             string
+	InsecureSkipVerify  bool
+	Token               string
+	Timeout             time.Duration
+	Consistency         string
+	Watch               bool
+	WatchWaitTime       time.Duration
+	WatchIdleTimeout    time.Duration
+	MinPassing          int
+	MinPassingPercent   float64
+	WarningAsPassing    bool
+	FailoverDatacenters []string
+	FailoverPolicy      string
+	AgentLocal          bool
+	ZoneMetaKey         string
+	Rise                int
+	Fall                int
 }
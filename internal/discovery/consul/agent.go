@@ -0,0 +1,152 @@
+package consul
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// agentLocal returns true if only instances registered in the local Consul agent
+// should be evaluated. The mode is enabled by the configuration or the local query parameter.
+func agentLocal(ctx *fiber.Ctx, cfg config.Consul) (bool, error) {
+	if v := ctx.Query("local"); v != "" {
+		local, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, xerrors.Errorf("invalid value, local: %s", v)
+		}
+		return local, nil
+	}
+
+	return cfg.AgentLocal, nil
+}
+
+// isServiceHealthyLocally returns true if the local agent has enough passing instances
+// of the service. The datacenter and failover datacenters aren't taken into account.
+func (c *Client) isServiceHealthyLocally(ctx *fiber.Ctx, q query, t threshold) (bool, error) {
+	services, err := c.agentServices(q)
+	if err != nil {
+		return false, err
+	}
+
+	statuses := make([]string, 0, len(services))
+	for _, service := range services {
+		statuses = append(statuses, service.AggregatedStatus)
+	}
+
+	agent := c.agent()
+
+	// The node of the agent is either in the zone or not, all its instances as well.
+	zone := ctx.Query("zone")
+	if zone != "" && agent.meta[c.Config.Consul.ZoneMetaKey] != zone {
+		statuses = nil
+	}
+
+	status := t.countStatuses(statuses)
+	status.Datacenter = agent.datacenter
+	status.Node = agent.node
+	status.Zone = zone
+	endpoints.Set(ctx, status)
+
+	healthy := status.Ready >= status.Required
+
+	c.Logger.Debug("Consul agent service instances",
+		zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+		zap.String("namespace", q.namespace),
+		zap.String("service", q.service),
+		zap.String("node", status.Node),
+		zap.String("zone", status.Zone),
+		zap.Int("passing", status.Ready),
+		zap.Int("warning", status.Warning),
+		zap.Int("not_passing", status.NotReady),
+		zap.Int("required", status.Required),
+		zap.Bool("healthy", healthy),
+	)
+
+	return healthy, nil
+}
+
+// agentServices returns the instances of the service registered in the local agent
+// that have all tags of the query. The filter expression is applied by the agent.
+func (c *Client) agentServices(q query) ([]capi.AgentServiceChecksInfo, error) {
+	options := &capi.QueryOptions{
+		Namespace: q.namespace,
+		Partition: q.partition,
+		Filter:    q.filter,
+	}
+
+	start := time.Now()
+	_, services, err := c.client.Agent().AgentHealthServiceByNameOpts(q.service, options)
+	c.Metrics.ObserveDiscovery(source, "agent_health_service", start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	matching := make([]capi.AgentServiceChecksInfo, 0, len(services))
+	for _, service := range services {
+		if service.Service != nil && hasTags(service.Service.Tags, q.tags) {
+			matching = append(matching, service)
+		}
+	}
+
+	return matching, nil
+}
+
+// agentInfo describes the local Consul agent.
+type agentInfo struct {
+	datacenter string
+	node       string
+	meta       map[string]string
+}
+
+// agent returns the description of the local Consul agent, it's read until the first
+// successful read. An empty description is returned if the agent can't be read.
+func (c *Client) agent() agentInfo {
+	c.agentMu.Lock()
+	defer c.agentMu.Unlock()
+
+	if c.agentInfo != nil {
+		return *c.agentInfo
+	}
+
+	self, err := c.client.Agent().Self()
+	if err != nil {
+		c.Logger.Warn("unable to read the configuration of the Consul agent", zap.Error(err))
+		return agentInfo{}
+	}
+
+	info := &agentInfo{meta: map[string]string{}}
+	info.datacenter, _ = self["Config"]["Datacenter"].(string)
+	info.node, _ = self["Config"]["NodeName"].(string)
+	for key, value := range self["Meta"] {
+		info.meta[key], _ = value.(string)
+	}
+	c.agentInfo = info
+
+	return *info
+}
+
+// hasTags returns true if tags contain all of the required tags.
+func hasTags(tags, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, tag := range tags {
+			if tag == r {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
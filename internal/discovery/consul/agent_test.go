@@ -0,0 +1,157 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+)
+
+func TestAgentLocal(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/agent/self":
+			_ = json.NewEncoder(w).Encode(map[string]map[string]interface{}{
+				"Config": {"Datacenter": "dc1", "NodeName": "node-a"},
+				"Meta":   {"zone": "zone-a"},
+			})
+		case "/v1/agent/health/service/name/redis":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode([]capi.AgentServiceChecksInfo{
+				{AggregatedStatus: capi.HealthPassing, Service: &capi.AgentService{Service: "redis", Tags: []string{"primary"}}},
+				{AggregatedStatus: capi.HealthWarning, Service: &capi.AgentService{Service: "redis"}},
+			})
+		case "/v1/agent/health/service/name/memcached":
+			w.WriteHeader(http.StatusNotFound)
+		case "/v1/health/service/memcached":
+			_ = json.NewEncoder(w).Encode([]*capi.ServiceEntry{{Service: &capi.AgentService{Service: "memcached"}}})
+		default:
+			_, _ = w.Write([]byte("[]"))
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.New(config.WithLogger(logger))
+	assert.NoError(t, cfg.SetDefault())
+	cfg.Consul.Enabled = true
+	cfg.Consul.Address = server.Listener.Addr().String()
+	cfg.Consul.AgentLocal = true
+
+	client, err := New(&Client{Logger: logger, Config: cfg})
+	assert.NoError(t, err)
+	defer client.Close()
+
+	table := []struct {
+		path     string
+		exists   bool
+		healthy  bool
+		expected endpoints.Status
+	}{
+		{
+			path:     "/health/consul/redis",
+			exists:   true,
+			healthy:  true,
+			expected: endpoints.Status{Ready: 1, NotReady: 1, Warning: 1, Required: 1, Datacenter: "dc1", Node: "node-a"},
+		},
+		{
+			path:     "/health/consul/redis?minPassing=2&tag=primary",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 1, Required: 2, Datacenter: "dc1", Node: "node-a"},
+		},
+		{
+			path:     "/health/consul/redis?zone=zone-a",
+			exists:   true,
+			healthy:  true,
+			expected: endpoints.Status{Ready: 1, NotReady: 1, Warning: 1, Required: 1, Datacenter: "dc1", Node: "node-a", Zone: "zone-a"},
+		},
+		{
+			path:     "/health/consul/redis?zone=zone-b",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Required: 1, Datacenter: "dc1", Node: "node-a", Zone: "zone-b"},
+		},
+		{
+			// The service is registered, but not in the local agent.
+			path:     "/health/consul/memcached",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Required: 1, Datacenter: "dc1", Node: "node-a"},
+		},
+		{
+			path:   "/health/consul/missing",
+			exists: false,
+		},
+	}
+
+	for _, item := range table {
+		app := fiber.New()
+		app.Get("/health/consul/:service", func(ctx *fiber.Ctx) error {
+			exists, err := client.IsServiceExists(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, item.exists, exists, item.path)
+			if !exists {
+				return nil
+			}
+
+			healthy, err := client.IsServiceHealthy(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, item.healthy, healthy, item.path)
+
+			status, _ := endpoints.Get(ctx)
+			assert.Equal(t, item.expected, status, item.path)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost"+item.path, nil))
+		assert.NoError(t, err)
+	}
+}
+
+func TestAgentRetry(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/agent/self" {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+
+		// The first read fails.
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]map[string]interface{}{
+			"Config": {"Datacenter": "dc1", "NodeName": "node-a"},
+		})
+	}))
+	defer server.Close()
+
+	cfg := config.New(config.WithLogger(logger))
+	assert.NoError(t, cfg.SetDefault())
+	cfg.Consul.Enabled = true
+	cfg.Consul.Address = server.Listener.Addr().String()
+
+	client, err := New(&Client{Logger: logger, Config: cfg})
+	assert.NoError(t, err)
+	defer client.Close()
+
+	assert.Equal(t, agentInfo{}, client.agent())
+	assert.Equal(t, "node-a", client.agent().node)
+
+	// A successful read is kept.
+	assert.Equal(t, "dc1", client.agent().datacenter)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
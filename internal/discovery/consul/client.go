@@ -1,22 +1,51 @@
 package consul
 
 import (
+	"sync"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	capi "github.com/hashicorp/consul/api"
 	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
 	"go.uber.org/zap"
 	"golang.org/x/xerrors"
 )
 
+// source is used to label metrics of the Consul API requests.
+const source = "consul"
+
 type Client struct {
-	Logger *zap.Logger
-	Config *config.Config
+	Logger  *zap.Logger
+	Config  *config.Config
+	Metrics *metrics.Metrics
 
 	client       *capi.Client
 	consulConfig *capi.Config
+	cache        *watchCache
+
+	agentMu   sync.Mutex
+	agentInfo *agentInfo
 }
 
 func New(c *Client) (*Client, error) {
+	client, err := newClient(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Config.Consul.Watch {
+		if err := client.startWatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// newClient initializes the client without the watch cache.
+func newClient(c *Client) (*Client, error) {
 	if !c.Config.Consul.Enabled {
 		return nil, xerrors.New("Consul client is disabled. You can enabled it in the configuration file")
 	}
@@ -59,25 +88,54 @@ func New(c *Client) (*Client, error) {
 }
 
 func (c *Client) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
-	namespace := ctx.Params("namespace")
-	service := ctx.Params("service")
+	q := newQuery(ctx)
 	requestID := ctx.GetRespHeader("X-Request-Id")
-	queryOptions := &capi.QueryOptions{}
 
-	if namespace != "" {
-		queryOptions.Namespace = namespace
+	local, err := agentLocal(ctx, c.Config.Consul)
+	if err != nil {
+		return false, err
+	}
+
+	// Instances registered in the local agent exist in the catalog as well, the
+	// catalog is read only if there are none.
+	if local {
+		services, err := c.agentServices(q)
+		if err != nil {
+			return false, err
+		}
+
+		if len(services) > 0 {
+			return true, nil
+		}
 	}
 
-	s, _, err := c.client.Catalog().Service(service, ctx.Query("tag"), queryOptions)
+	f, err := newFailover(ctx, c.Config.Consul)
 	if err != nil {
 		return false, err
 	}
 
-	if len(s) == 0 {
+	exists, err := f.evaluate(q, func(q query) (bool, error) {
+		exists, err := c.serviceExists(q)
+		if err != nil {
+			c.Logger.Warn("unable to read Consul service",
+				zap.String("request_id", requestID),
+				zap.String("service", q.service),
+				zap.String("datacenter", q.datacenter),
+				zap.Error(err),
+			)
+		}
+		return exists, err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if !exists {
 		c.Logger.Debug("Consul service doesn't exist",
 			zap.String("request_id", requestID),
-			zap.String("namespace", namespace),
-			zap.String("service", service),
+			zap.String("namespace", q.namespace),
+			zap.String("service", q.service),
+			zap.String("datacenter", q.datacenter),
 		)
 		return false, nil
 	}
@@ -85,27 +143,137 @@ func (c *Client) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
 	return true, nil
 }
 
+// serviceExists returns true if the service has any instance in the datacenter of the
+// query. Instances are read from the health endpoint, as the filter of the query uses
+// its grammar, which differs from the one of the catalog endpoint.
+func (c *Client) serviceExists(q query) (bool, error) {
+	services, err := c.serviceEntries(q)
+	return len(services) > 0, err
+}
+
+// IsServiceHealthy returns true if the service has enough passing instances in the
+// primary datacenter or, depending on the failover policy, in any failover datacenter.
+// The reported endpoints belong to the datacenter that satisfied the check, or to the
+// first reachable one.
 func (c *Client) IsServiceHealthy(ctx *fiber.Ctx) (bool, error) {
-	namespace := ctx.Params("namespace")
-	service := ctx.Params("service")
-	queryOptions := &capi.QueryOptions{}
+	q := newQuery(ctx)
+	requestID := ctx.GetRespHeader("X-Request-Id")
 
-	if namespace != "" {
-		queryOptions.Namespace = namespace
+	t, err := newThreshold(ctx, c.Config.Consul)
+	if err != nil {
+		return false, err
 	}
 
-	serviceEntry, _, err := c.client.Health().Service(service, ctx.Query("tag"), true, queryOptions)
+	local, err := agentLocal(ctx, c.Config.Consul)
 	if err != nil {
 		return false, err
 	}
 
-	if len(serviceEntry) == 0 {
-		return false, nil
+	if local {
+		return c.isServiceHealthyLocally(ctx, q, t)
 	}
 
-	return true, nil
+	f, err := newFailover(ctx, c.Config.Consul)
+	if err != nil {
+		return false, err
+	}
+
+	zone := ctx.Query("zone")
+
+	var reported *endpoints.Status
+
+	healthy, err := f.evaluate(q, func(q query) (bool, error) {
+		services, err := c.serviceEntries(q)
+		if err != nil {
+			c.Logger.Warn("unable to read Consul service instances",
+				zap.String("request_id", requestID),
+				zap.String("service", q.service),
+				zap.String("datacenter", q.datacenter),
+				zap.Error(err),
+			)
+			return false, err
+		}
+
+		status := t.countInstances(inZone(services, c.Config.Consul.ZoneMetaKey, zone))
+		status.Datacenter = q.datacenter
+		status.Zone = zone
+		healthy := status.Ready >= status.Required
+
+		c.Logger.Debug("Consul service instances",
+			zap.String("request_id", requestID),
+			zap.String("namespace", q.namespace),
+			zap.String("service", q.service),
+			zap.String("datacenter", q.datacenter),
+			zap.String("zone", zone),
+			zap.Int("passing", status.Ready),
+			zap.Int("warning", status.Warning),
+			zap.Int("not_passing", status.NotReady),
+			zap.Int("required", status.Required),
+			zap.Bool("healthy", healthy),
+		)
+
+		if healthy || reported == nil {
+			reported = &status
+		}
+
+		return healthy, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if reported != nil {
+		if reported.Datacenter == "" && len(f.queries(q)) > 1 {
+			reported.Datacenter = c.agent().datacenter
+		}
+		endpoints.Set(ctx, *reported)
+	}
+
+	return healthy, nil
+}
+
+// serviceEntries returns all instances of the service along with their checks.
+func (c *Client) serviceEntries(q query) ([]*capi.ServiceEntry, error) {
+	if c.cache != nil {
+		return c.cache.get(q)
+	}
+
+	start := time.Now()
+	services, _, err := c.client.Health().ServiceMultipleTags(q.service, q.tags, false, q.options(c.Config.Consul.Consistency))
+	c.Metrics.ObserveDiscovery(source, "health_service", start, err)
+
+	return services, err
+}
+
+// startWatch creates the watch cache. Blocking queries use a separate client, the
+// timeout of its requests is extended by the wait time along with the jitter added
+// by Consul (up to 1/16 of the wait time).
+func (c *Client) startWatch() error {
+	watchConfig := *c.consulConfig
+
+	httpClient, err := capi.NewHttpClient(watchConfig.Transport, watchConfig.TLSConfig)
+	if err != nil {
+		return err
+	}
+	watchConfig.HttpClient = httpClient
+	if c.Config.Consul.Timeout != 0 {
+		waitTime := c.Config.Consul.WatchWaitTime
+		watchConfig.HttpClient.Timeout = c.Config.Consul.Timeout + waitTime + waitTime/16 //nolint:gomnd
+	}
+
+	client, err := capi.NewClient(&watchConfig)
+	if err != nil {
+		return err
+	}
+
+	c.cache = newWatchCache(client, c.Logger, c.Metrics, c.Config.Consul.Consistency,
+		c.Config.Consul.WatchWaitTime, c.Config.Consul.WatchIdleTimeout)
+
+	return nil
 }
 
+// Close releases idle connections. The watch cache isn't stopped, it keeps requested
+// services up to date for as long as the process runs.
 func (c *Client) Close() {
 	c.consulConfig.HttpClient.CloseIdleConnections()
 }
@@ -0,0 +1,104 @@
+package consul
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// FailoverPolicyAny considers the service healthy if any datacenter meets the threshold.
+	FailoverPolicyAny = "any"
+	// FailoverPolicyPrimary evaluates failover datacenters only if the primary datacenter
+	// is unreachable.
+	FailoverPolicyPrimary = "primary"
+)
+
+// failover defines the ordered list of datacenters evaluated after the primary one.
+type failover struct {
+	datacenters []string
+	policy      string
+}
+
+// newFailover returns the failover from the configuration overridden by the query parameters.
+func newFailover(ctx *fiber.Ctx, cfg config.Consul) (failover, error) {
+	f := failover{
+		datacenters: cfg.FailoverDatacenters,
+		policy:      strings.ToLower(cfg.FailoverPolicy),
+	}
+
+	if v := ctx.Query("failover"); v != "" {
+		f.datacenters = strings.Split(v, ",")
+	}
+
+	if v := ctx.Query("failoverPolicy"); v != "" {
+		f.policy = strings.ToLower(v)
+	}
+
+	switch f.policy {
+	case "":
+		f.policy = FailoverPolicyAny
+	case FailoverPolicyAny, FailoverPolicyPrimary:
+	default:
+		return f, xerrors.Errorf("invalid failover policy, failoverPolicy: %s", f.policy)
+	}
+
+	return f, nil
+}
+
+// queries returns the query of the primary datacenter followed by the queries of
+// the failover datacenters. Duplicated datacenters are evaluated only once.
+func (f failover) queries(q query) []query {
+	queries := []query{q}
+	seen := map[string]bool{q.datacenter: true}
+
+	for _, dc := range f.datacenters {
+		dc = strings.TrimSpace(dc)
+		if dc == "" || seen[dc] {
+			continue
+		}
+		seen[dc] = true
+
+		target := q
+		target.datacenter = dc
+		queries = append(queries, target)
+	}
+
+	return queries
+}
+
+// evaluate calls fn for each datacenter in order until it succeeds. Unreachable
+// datacenters are skipped, the error is returned only if none of them can be reached.
+func (f failover) evaluate(q query, fn func(q query) (bool, error)) (bool, error) {
+	var (
+		firstErr error
+		reached  bool
+	)
+
+	for i, target := range f.queries(q) {
+		ok, err := fn(target)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		if ok {
+			return true, nil
+		}
+		reached = true
+
+		if i == 0 && f.policy == FailoverPolicyPrimary {
+			break
+		}
+	}
+
+	if reached {
+		return false, nil
+	}
+
+	return false, firstErr
+}
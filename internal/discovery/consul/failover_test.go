@@ -0,0 +1,220 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	"golang.org/x/xerrors"
+)
+
+func TestFailoverQueries(t *testing.T) {
+	t.Parallel()
+
+	f := failover{datacenters: []string{"dc2", " dc3", "", "dc1", "dc2"}}
+
+	var datacenters []string
+	for _, q := range f.queries(query{service: "redis", datacenter: "dc1"}) {
+		assert.Equal(t, "redis", q.service)
+		datacenters = append(datacenters, q.datacenter)
+	}
+
+	assert.Equal(t, []string{"dc1", "dc2", "dc3"}, datacenters)
+}
+
+func TestFailoverEvaluate(t *testing.T) {
+	t.Parallel()
+
+	errUnreachable := xerrors.New("unreachable")
+
+	table := []struct {
+		name     string
+		policy   string
+		results  map[string]error
+		healthy  map[string]bool
+		expected bool
+		err      bool
+	}{
+		{
+			name:     "primary healthy",
+			policy:   FailoverPolicyAny,
+			healthy:  map[string]bool{"dc1": true},
+			expected: true,
+		},
+		{
+			name:     "failover healthy",
+			policy:   FailoverPolicyAny,
+			healthy:  map[string]bool{"dc3": true},
+			expected: true,
+		},
+		{
+			name:     "primary policy ignores failover if primary is reachable",
+			policy:   FailoverPolicyPrimary,
+			healthy:  map[string]bool{"dc2": true},
+			expected: false,
+		},
+		{
+			name:     "primary policy fails over if primary is unreachable",
+			policy:   FailoverPolicyPrimary,
+			results:  map[string]error{"dc1": errUnreachable},
+			healthy:  map[string]bool{"dc2": true},
+			expected: true,
+		},
+		{
+			name:     "unreachable datacenters are skipped",
+			policy:   FailoverPolicyAny,
+			results:  map[string]error{"dc1": errUnreachable, "dc2": errUnreachable},
+			expected: false,
+		},
+		{
+			name:    "all datacenters unreachable",
+			policy:  FailoverPolicyAny,
+			results: map[string]error{"dc1": errUnreachable, "dc2": errUnreachable, "dc3": errUnreachable},
+			err:     true,
+		},
+	}
+
+	for _, item := range table {
+		item := item
+
+		t.Run(item.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := failover{datacenters: []string{"dc2", "dc3"}, policy: item.policy}
+
+			healthy, err := f.evaluate(query{datacenter: "dc1"}, func(q query) (bool, error) {
+				return item.healthy[q.datacenter], item.results[q.datacenter]
+			})
+			if item.err {
+				assert.ErrorIs(t, err, errUnreachable)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, item.expected, healthy)
+		})
+	}
+}
+
+func TestNewFailover(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Consul{FailoverDatacenters: []string{"dc2"}}
+
+	table := []struct {
+		query    string
+		expected failover
+		err      bool
+	}{
+		{query: "", expected: failover{datacenters: []string{"dc2"}, policy: FailoverPolicyAny}},
+		{
+			query:    "?failover=dc3,dc4&failoverPolicy=Primary",
+			expected: failover{datacenters: []string{"dc3", "dc4"}, policy: FailoverPolicyPrimary},
+		},
+		{query: "?failoverPolicy=all", err: true},
+	}
+
+	for _, item := range table {
+		item := item
+
+		app := fiber.New()
+		app.Get("/", func(ctx *fiber.Ctx) error {
+			f, err := newFailover(ctx, cfg)
+			if item.err {
+				assert.Error(t, err)
+				return nil
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, item.expected, f)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost/"+item.query, nil))
+		assert.NoError(t, err)
+	}
+}
+
+func TestIsServiceHealthyFailover(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	statuses := map[string]string{
+		"dc1": capi.HealthCritical,
+		"dc2": capi.HealthPassing,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/agent/self" {
+			_ = json.NewEncoder(w).Encode(map[string]map[string]interface{}{
+				"Config": {"Datacenter": "dc1"},
+			})
+			return
+		}
+
+		dc := r.URL.Query().Get("dc")
+		if dc == "" {
+			dc = "dc1"
+		}
+
+		status, ok := statuses[dc]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("X-Consul-Index", "1")
+		_ = json.NewEncoder(w).Encode([]*capi.ServiceEntry{
+			{
+				Service: &capi.AgentService{Service: "redis"},
+				Checks:  capi.HealthChecks{{Status: status}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	cfg := config.New(config.WithLogger(logger))
+	assert.NoError(t, cfg.SetDefault())
+	cfg.Consul.Enabled = true
+	cfg.Consul.Address = server.Listener.Addr().String()
+	cfg.Consul.FailoverDatacenters = []string{"dc3", "dc2"}
+
+	client, err := New(&Client{Logger: logger, Config: cfg})
+	assert.NoError(t, err)
+	defer client.Close()
+
+	table := []struct {
+		query      string
+		healthy    bool
+		datacenter string
+	}{
+		{query: "", healthy: true, datacenter: "dc2"},
+		{query: "?failoverPolicy=primary", healthy: false, datacenter: "dc1"},
+		{query: "?failover=dc3", healthy: false, datacenter: "dc1"},
+		{query: "?dc=dc3&failoverPolicy=primary", healthy: true, datacenter: "dc2"},
+	}
+
+	for _, item := range table {
+		var status endpoints.Status
+
+		app := fiber.New()
+		app.Get("/health/consul/:service", func(ctx *fiber.Ctx) error {
+			healthy, err := client.IsServiceHealthy(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, item.healthy, healthy, item.query)
+
+			status, _ = endpoints.Get(ctx)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost/health/consul/redis"+item.query, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, item.datacenter, status.Datacenter, item.query)
+	}
+}
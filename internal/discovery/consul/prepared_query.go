@@ -0,0 +1,120 @@
+package consul
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"go.uber.org/zap"
+)
+
+// preparedQueryKey is the key of the executed prepared query in the request context.
+const preparedQueryKey = "healthgroup.consul.preparedQuery"
+
+// PreparedQuery evaluates the instances returned by a prepared query. Tag filters,
+// nearness and failover are defined by the prepared query itself.
+type PreparedQuery struct {
+	*Client
+}
+
+// NewPreparedQuery returns the prepared query adapter. Prepared queries are always
+// executed, they aren't answered from the watch cache, so the cache isn't started.
+func NewPreparedQuery(c *Client) (*PreparedQuery, error) {
+	client, err := newClient(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreparedQuery{Client: client}, nil
+}
+
+// IsServiceExists returns false if the prepared query doesn't exist.
+func (p *PreparedQuery) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
+	requestID := ctx.GetRespHeader("X-Request-Id")
+
+	_, err := p.execute(ctx)
+
+	var statusErr capi.StatusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+		p.Logger.Debug("Consul prepared query doesn't exist",
+			zap.String("request_id", requestID),
+			zap.String("query", ctx.Params("query")),
+		)
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsServiceHealthy returns true if the prepared query returned enough passing instances.
+func (p *PreparedQuery) IsServiceHealthy(ctx *fiber.Ctx) (bool, error) {
+	requestID := ctx.GetRespHeader("X-Request-Id")
+
+	t, err := newThreshold(ctx, p.Config.Consul)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := p.execute(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	services := make([]*capi.ServiceEntry, 0, len(resp.Nodes))
+	for i := range resp.Nodes {
+		services = append(services, &resp.Nodes[i])
+	}
+
+	zone := ctx.Query("zone")
+
+	status := t.countInstances(inZone(services, p.Config.Consul.ZoneMetaKey, zone))
+	status.Datacenter = resp.Datacenter
+	status.Zone = zone
+	endpoints.Set(ctx, status)
+
+	healthy := status.Ready >= status.Required
+
+	p.Logger.Debug("Consul prepared query instances",
+		zap.String("request_id", requestID),
+		zap.String("query", ctx.Params("query")),
+		zap.String("service", resp.Service),
+		zap.String("datacenter", resp.Datacenter),
+		zap.Int("failovers", resp.Failovers),
+		zap.Int("passing", status.Ready),
+		zap.Int("warning", status.Warning),
+		zap.Int("not_passing", status.NotReady),
+		zap.Int("required", status.Required),
+		zap.Bool("healthy", healthy),
+	)
+
+	return healthy, nil
+}
+
+// execute executes the prepared query of the request. The response is kept in the
+// request context, so the query is executed only once per request.
+func (p *PreparedQuery) execute(ctx *fiber.Ctx) (*capi.PreparedQueryExecuteResponse, error) {
+	if resp, ok := ctx.Locals(preparedQueryKey).(*capi.PreparedQueryExecuteResponse); ok {
+		return resp, nil
+	}
+
+	options := query{datacenter: ctx.Query("dc")}.options(p.Config.Consul.Consistency)
+
+	start := time.Now()
+	resp, _, err := p.client.PreparedQuery().Execute(ctx.Params("query"), options)
+	p.Metrics.ObserveDiscovery(source, "execute_prepared_query", start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Locals(preparedQueryKey, resp)
+
+	return resp, nil
+}
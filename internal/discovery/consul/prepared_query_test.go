@@ -0,0 +1,106 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+)
+
+func TestPreparedQuery(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	var executions int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&executions, 1)
+
+		if r.URL.Path != "/v1/query/redis-nearest/execute" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("Query not found"))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(capi.PreparedQueryExecuteResponse{
+			Service:    "redis",
+			Datacenter: "dc2",
+			Failovers:  1,
+			Nodes: []capi.ServiceEntry{
+				{Service: &capi.AgentService{Service: "redis"}, Checks: capi.HealthChecks{{Status: capi.HealthPassing}}},
+				{Service: &capi.AgentService{Service: "redis"}, Checks: capi.HealthChecks{{Status: capi.HealthWarning}}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	cfg := config.New(config.WithLogger(logger))
+	assert.NoError(t, cfg.SetDefault())
+	cfg.Consul.Enabled = true
+	cfg.Consul.Address = server.Listener.Addr().String()
+	cfg.Consul.Watch = true
+
+	client, err := NewPreparedQuery(&Client{Logger: logger, Config: cfg})
+	assert.NoError(t, err)
+	defer client.Close()
+	assert.Nil(t, client.cache)
+
+	table := []struct {
+		path     string
+		exists   bool
+		healthy  bool
+		expected endpoints.Status
+	}{
+		{
+			path:     "/health/consul-query/redis-nearest",
+			exists:   true,
+			healthy:  true,
+			expected: endpoints.Status{Ready: 1, NotReady: 1, Warning: 1, Required: 1, Datacenter: "dc2"},
+		},
+		{
+			path:     "/health/consul-query/redis-nearest?minPassing=2",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 1, NotReady: 1, Warning: 1, Required: 2, Datacenter: "dc2"},
+		},
+		{
+			path:   "/health/consul-query/unknown",
+			exists: false,
+		},
+	}
+
+	for _, item := range table {
+		atomic.StoreInt32(&executions, 0)
+
+		app := fiber.New()
+		app.Get("/health/consul-query/:query", func(ctx *fiber.Ctx) error {
+			exists, err := client.IsServiceExists(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, item.exists, exists, item.path)
+			if !exists {
+				return nil
+			}
+
+			healthy, err := client.IsServiceHealthy(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, item.healthy, healthy, item.path)
+
+			status, _ := endpoints.Get(ctx)
+			assert.Equal(t, item.expected, status, item.path)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost"+item.path, nil))
+		assert.NoError(t, err)
+
+		// The prepared query is executed once per request.
+		assert.Equal(t, int32(1), atomic.LoadInt32(&executions), item.path)
+	}
+}
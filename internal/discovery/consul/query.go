@@ -0,0 +1,78 @@
+package consul
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+)
+
+const (
+	ConsistencyDefault    = "default"
+	ConsistencyStale      = "stale"
+	ConsistencyConsistent = "consistent"
+)
+
+// query identifies the instances of a service requested from Consul.
+type query struct {
+	service    string
+	tags       []string
+	namespace  string
+	datacenter string
+	partition  string
+	peer       string
+	filter     string
+}
+
+// newQuery returns the query of the request. Instances have to match all tags,
+// the tag parameter can be repeated.
+func newQuery(ctx *fiber.Ctx) query {
+	q := query{
+		service:    ctx.Params("service"),
+		namespace:  ctx.Params("namespace"),
+		datacenter: ctx.Query("dc"),
+		partition:  ctx.Query("partition"),
+		peer:       ctx.Query("peer"),
+		filter:     ctx.Query("filter"),
+	}
+
+	for _, tag := range ctx.Context().QueryArgs().PeekMulti("tag") {
+		if len(tag) > 0 {
+			q.tags = append(q.tags, string(tag))
+		}
+	}
+	sort.Strings(q.tags)
+
+	return q
+}
+
+// key identifies the query in the watch cache.
+func (q query) key() string {
+	return strings.Join([]string{
+		q.datacenter, q.partition, q.peer, q.namespace, q.service, strings.Join(q.tags, ","), q.filter,
+	}, "/")
+}
+
+// options returns query options along with the consistency mode.
+func (q query) options(consistency string) *capi.QueryOptions {
+	options := &capi.QueryOptions{}
+
+	if q.namespace != "" {
+		options.Namespace = q.namespace
+	}
+
+	options.Datacenter = q.datacenter
+	options.Partition = q.partition
+	options.Peer = q.peer
+	options.Filter = q.filter
+
+	switch consistency {
+	case ConsistencyStale:
+		options.AllowStale = true
+	case ConsistencyConsistent:
+		options.RequireConsistent = true
+	}
+
+	return options
+}
@@ -0,0 +1,116 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/log"
+)
+
+func TestNewQuery(t *testing.T) {
+	t.Parallel()
+
+	var q query
+
+	app := fiber.New()
+	app.Get("/health/consul/:namespace/:service", func(ctx *fiber.Ctx) error {
+		q = newQuery(ctx)
+		return nil
+	})
+
+	path := `/health/consul/payments/redis?tag=primary&tag=eu&dc=dc2&partition=team-a&peer=cluster-2&filter=Service.Meta.version%20%3D%3D%20%222%22`
+	_, err := app.Test(httptest.NewRequest("GET", "http://localhost"+path, nil))
+	assert.NoError(t, err)
+
+	assert.Equal(t, query{
+		service:    "redis",
+		tags:       []string{"eu", "primary"},
+		namespace:  "payments",
+		datacenter: "dc2",
+		partition:  "team-a",
+		peer:       "cluster-2",
+		filter:     `Service.Meta.version == "2"`,
+	}, q)
+	assert.Equal(t, `dc2/team-a/cluster-2/payments/redis/eu,primary/Service.Meta.version == "2"`, q.key())
+
+	assert.Equal(t, &capi.QueryOptions{
+		Namespace:  "payments",
+		Datacenter: "dc2",
+		Partition:  "team-a",
+		Peer:       "cluster-2",
+		Filter:     `Service.Meta.version == "2"`,
+		AllowStale: true,
+	}, q.options(ConsistencyStale))
+}
+
+func TestFilter(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("filter")
+
+		// Selectors of the health endpoint aren't valid for the catalog endpoint.
+		if r.URL.Path != "/v1/health/service/redis" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var services []*capi.ServiceEntry
+		if filter == "" || filter == `Service.Meta.version == "2"` {
+			services = append(services, &capi.ServiceEntry{
+				Service: &capi.AgentService{Service: "redis", Meta: map[string]string{"version": "2"}},
+				Checks:  capi.HealthChecks{{Status: capi.HealthPassing}},
+			})
+		}
+
+		w.Header().Set("X-Consul-Index", "1")
+		_ = json.NewEncoder(w).Encode(services)
+	}))
+	defer server.Close()
+
+	cfg := config.New(config.WithLogger(logger))
+	assert.NoError(t, cfg.SetDefault())
+	cfg.Consul.Enabled = true
+	cfg.Consul.Address = server.Listener.Addr().String()
+	cfg.Consul.Watch = false
+
+	client, err := New(&Client{Logger: logger, Config: cfg})
+	assert.NoError(t, err)
+	defer client.Close()
+
+	table := []struct {
+		query   string
+		exists  bool
+		healthy bool
+	}{
+		{query: "", exists: true, healthy: true},
+		{query: "?filter=Service.Meta.version%20%3D%3D%20%222%22", exists: true, healthy: true},
+		{query: "?filter=Service.Meta.version%20%3D%3D%20%223%22", exists: false},
+	}
+
+	for _, item := range table {
+		app := fiber.New()
+		app.Get("/health/consul/:service", func(ctx *fiber.Ctx) error {
+			exists, err := client.IsServiceExists(ctx)
+			assert.NoError(t, err, item.query)
+			assert.Equal(t, item.exists, exists, item.query)
+			if !exists {
+				return nil
+			}
+
+			healthy, err := client.IsServiceHealthy(ctx)
+			assert.NoError(t, err, item.query)
+			assert.Equal(t, item.healthy, healthy, item.query)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost/health/consul/redis"+item.query, nil))
+		assert.NoError(t, err)
+	}
+}
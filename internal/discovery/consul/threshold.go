@@ -0,0 +1,109 @@
+package consul
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"golang.org/x/xerrors"
+)
+
+// threshold defines how many instances of a service have to pass for the service to be healthy.
+type threshold struct {
+	minPassing        int
+	minPassingPercent float64
+	warningAsPassing  bool
+}
+
+// newThreshold returns the threshold from the configuration overridden by the query parameters.
+func newThreshold(ctx *fiber.Ctx, cfg config.Consul) (threshold, error) {
+	t := threshold{
+		minPassing:        cfg.MinPassing,
+		minPassingPercent: cfg.MinPassingPercent,
+		warningAsPassing:  cfg.WarningAsPassing,
+	}
+
+	if v := ctx.Query("minPassing"); v != "" {
+		minPassing, err := strconv.Atoi(v)
+		if err != nil || minPassing < 1 {
+			return t, xerrors.Errorf("invalid minimum of passing instances, minPassing: %s", v)
+		}
+		t.minPassing = minPassing
+	}
+
+	if v := ctx.Query("minPassingPercent"); v != "" {
+		percent, err := strconv.ParseFloat(v, 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return t, xerrors.Errorf("invalid minimum percentage of passing instances, minPassingPercent: %s", v)
+		}
+		t.minPassingPercent = percent
+	}
+
+	if v := ctx.Query("warningAsPassing"); v != "" {
+		warningAsPassing, err := strconv.ParseBool(v)
+		if err != nil {
+			return t, xerrors.Errorf("invalid value, warningAsPassing: %s", v)
+		}
+		t.warningAsPassing = warningAsPassing
+	}
+
+	return t, nil
+}
+
+// required returns the number of passing instances required out of total. Both the
+// number and the percentage have to be met, at least one passing instance is always
+// required.
+func (t threshold) required(total int) int {
+	required := t.minPassing
+
+	percent := int(math.Ceil(float64(total) * t.minPassingPercent / 100)) //nolint:gomnd
+	if percent > required {
+		required = percent
+	}
+
+	if required < 1 {
+		return 1
+	}
+
+	return required
+}
+
+// countInstances returns the number of passing, warning and other instances. An
+// instance is passing if all its checks, including node checks, are passing.
+func (t threshold) countInstances(services []*capi.ServiceEntry) endpoints.Status {
+	statuses := make([]string, 0, len(services))
+	for _, service := range services {
+		statuses = append(statuses, service.Checks.AggregatedStatus())
+	}
+
+	return t.countStatuses(statuses)
+}
+
+// countStatuses returns the number of passing, warning and other instances given
+// the aggregated status of each instance.
+func (t threshold) countStatuses(statuses []string) endpoints.Status {
+	var status endpoints.Status
+
+	for _, aggregated := range statuses {
+		switch aggregated {
+		case capi.HealthPassing:
+			status.Ready++
+		case capi.HealthWarning:
+			status.Warning++
+			if t.warningAsPassing {
+				status.Ready++
+			} else {
+				status.NotReady++
+			}
+		default:
+			status.NotReady++
+		}
+	}
+
+	status.Required = t.required(len(statuses))
+
+	return status
+}
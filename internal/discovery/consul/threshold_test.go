@@ -0,0 +1,107 @@
+package consul
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	capi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+)
+
+func TestNewThreshold(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Consul{MinPassing: 1, MinPassingPercent: 10}
+
+	table := []struct {
+		query    string
+		expected threshold
+		err      bool
+	}{
+		{query: "", expected: threshold{minPassing: 1, minPassingPercent: 10}},
+		{
+			query:    "?minPassing=3&minPassingPercent=50&warningAsPassing=true",
+			expected: threshold{minPassing: 3, minPassingPercent: 50, warningAsPassing: true},
+		},
+		{query: "?minPassing=-1", err: true},
+		{query: "?minPassing=0", err: true},
+		{query: "?minPassingPercent=101", err: true},
+		{query: "?warningAsPassing=maybe", err: true},
+	}
+
+	for _, item := range table {
+		item := item
+
+		app := fiber.New()
+		app.Get("/", func(ctx *fiber.Ctx) error {
+			th, err := newThreshold(ctx, cfg)
+			if item.err {
+				assert.Error(t, err, item.query)
+			} else {
+				assert.NoError(t, err, item.query)
+				assert.Equal(t, item.expected, th, item.query)
+			}
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost/"+item.query, nil))
+		assert.NoError(t, err)
+	}
+}
+
+func TestCountInstances(t *testing.T) {
+	t.Parallel()
+
+	instance := func(status string) *capi.ServiceEntry {
+		return &capi.ServiceEntry{Checks: capi.HealthChecks{{Status: capi.HealthPassing}, {Status: status}}}
+	}
+
+	services := []*capi.ServiceEntry{
+		instance(capi.HealthPassing),
+		instance(capi.HealthPassing),
+		instance(capi.HealthWarning),
+		instance(capi.HealthCritical),
+	}
+
+	table := []struct {
+		desc     string
+		t        threshold
+		expected endpoints.Status
+	}{
+		{
+			desc:     "default",
+			t:        threshold{minPassing: 1},
+			expected: endpoints.Status{Ready: 2, NotReady: 2, Warning: 1, Required: 1},
+		},
+		{
+			desc:     "warning as passing",
+			t:        threshold{minPassing: 1, warningAsPassing: true},
+			expected: endpoints.Status{Ready: 3, NotReady: 1, Warning: 1, Required: 1},
+		},
+		{
+			desc:     "percentage",
+			t:        threshold{minPassing: 1, minPassingPercent: 75},
+			expected: endpoints.Status{Ready: 2, NotReady: 2, Warning: 1, Required: 3},
+		},
+		{
+			desc:     "count higher than percentage",
+			t:        threshold{minPassing: 4, minPassingPercent: 50},
+			expected: endpoints.Status{Ready: 2, NotReady: 2, Warning: 1, Required: 4},
+		},
+		{
+			desc:     "no minimum",
+			t:        threshold{},
+			expected: endpoints.Status{Ready: 2, NotReady: 2, Warning: 1, Required: 1},
+		},
+	}
+
+	for _, item := range table {
+		assert.Equal(t, item.expected, item.t.countInstances(services), item.desc)
+	}
+
+	// A service without instances is never healthy.
+	assert.Equal(t, endpoints.Status{Required: 1}, threshold{}.countInstances(nil))
+}
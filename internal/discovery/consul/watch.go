@@ -0,0 +1,155 @@
+package consul
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	capi "github.com/hashicorp/consul/api"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// watchRetryInterval is the delay before a failed blocking query is retried.
+const watchRetryInterval = time.Second
+
+// watchCache keeps the instances of requested services up to date by long-polling
+// blocking queries, one per query. Queries that haven't been requested for longer
+// than the idle timeout are stopped.
+type watchCache struct {
+	client      *capi.Client
+	logger      *zap.Logger
+	metrics     *metrics.Metrics
+	consistency string
+	waitTime    time.Duration
+	idleTimeout time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*watchEntry
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+type watchEntry struct {
+	// ready is closed once the first response is received.
+	ready      chan struct{}
+	services   []*capi.ServiceEntry
+	err        error
+	lastAccess time.Time
+}
+
+func newWatchCache(client *capi.Client, logger *zap.Logger, m *metrics.Metrics, consistency string,
+	waitTime, idleTimeout time.Duration,
+) *watchCache {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &watchCache{
+		client:      client,
+		logger:      logger,
+		metrics:     m,
+		consistency: consistency,
+		waitTime:    waitTime,
+		idleTimeout: idleTimeout,
+		entries:     map[string]*watchEntry{},
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// get returns all instances of the service matching the query along with their
+// checks. The first request of a query waits for the first response.
+func (w *watchCache) get(q query) ([]*capi.ServiceEntry, error) {
+	key := q.key()
+
+	w.mu.Lock()
+	entry, ok := w.entries[key]
+	if !ok {
+		entry = &watchEntry{ready: make(chan struct{})}
+		w.entries[key] = entry
+
+		w.logger.Debug("watch Consul service", zap.String("query", key))
+
+		go w.watch(key, q, entry)
+	}
+	entry.lastAccess = time.Now()
+	w.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return entry.services, entry.err
+}
+
+func (w *watchCache) watch(key string, q query, entry *watchEntry) {
+	var index uint64
+
+	once := sync.Once{}
+
+	for !w.idle(key, entry) {
+		options := q.options(w.consistency).WithContext(w.ctx)
+		options.WaitIndex = index
+		options.WaitTime = w.waitTime
+
+		start := time.Now()
+		services, meta, err := w.client.Health().ServiceMultipleTags(q.service, q.tags, false, options)
+		w.metrics.ObserveDiscovery(source, "watch_health_service", start, err)
+
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		w.mu.Lock()
+		if err != nil {
+			entry.err = err
+		} else {
+			entry.services, entry.err = services, nil
+		}
+		w.mu.Unlock()
+
+		once.Do(func() { close(entry.ready) })
+
+		if err != nil {
+			w.logger.Error("watch Consul service", zap.String("query", key), zap.Error(err))
+
+			index = 0
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(watchRetryInterval):
+			}
+
+			continue
+		}
+
+		// The index has to be reset if it goes backwards, e.g. after a snapshot is restored.
+		index = meta.LastIndex
+		if index < options.WaitIndex {
+			index = 0
+		}
+	}
+}
+
+// idle removes the entry if it hasn't been requested for longer than the idle timeout.
+func (w *watchCache) idle(key string, entry *watchEntry) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.idleTimeout == 0 || entry.lastAccess.IsZero() || time.Since(entry.lastAccess) <= w.idleTimeout {
+		return false
+	}
+
+	delete(w.entries, key)
+	w.logger.Debug("stop watching idle Consul service", zap.String("query", key))
+
+	return true
+}
+
+func (w *watchCache) close() {
+	w.cancel()
+}
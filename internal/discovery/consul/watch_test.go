@@ -0,0 +1,100 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	capi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/log"
+)
+
+func TestWatchCache(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	var (
+		index    int32 = 1
+		requests int32
+	)
+	status := atomic.Value{}
+	status.Store(capi.HealthPassing)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		// Block until the index changes, like Consul does.
+		wait, _ := strconv.Atoi(r.URL.Query().Get("index"))
+		for i := 0; i < 100 && int32(wait) >= atomic.LoadInt32(&index); i++ {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		w.Header().Set("X-Consul-Index", strconv.Itoa(int(atomic.LoadInt32(&index))))
+		_ = json.NewEncoder(w).Encode([]*capi.ServiceEntry{
+			{
+				Service: &capi.AgentService{Service: "redis"},
+				Checks:  capi.HealthChecks{{Status: status.Load().(string)}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := capi.NewClient(&capi.Config{Address: server.Listener.Addr().String()})
+	assert.NoError(t, err)
+
+	cache := newWatchCache(client, logger, nil, ConsistencyStale, time.Second, time.Minute)
+	defer cache.close()
+
+	services, err := cache.get(query{service: "redis"})
+	assert.NoError(t, err)
+	assert.Len(t, services, 1)
+	assert.Equal(t, capi.HealthPassing, services[0].Checks.AggregatedStatus())
+
+	// Requests are answered from memory while the blocking query is pending.
+	before := atomic.LoadInt32(&requests)
+	for i := 0; i < 10; i++ {
+		_, err := cache.get(query{service: "redis"})
+		assert.NoError(t, err)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&requests), before+1)
+
+	// A change unblocks the query and updates the cache.
+	status.Store(capi.HealthCritical)
+	atomic.AddInt32(&index, 1)
+
+	assert.Eventually(t, func() bool {
+		services, err := cache.get(query{service: "redis"})
+		return err == nil && services[0].Checks.AggregatedStatus() == capi.HealthCritical
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWatchCacheIdle(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Consul-Index", "1")
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	client, err := capi.NewClient(&capi.Config{Address: server.Listener.Addr().String()})
+	assert.NoError(t, err)
+
+	cache := newWatchCache(client, logger, nil, ConsistencyDefault, 10*time.Millisecond, 20*time.Millisecond)
+	defer cache.close()
+
+	services, err := cache.get(query{service: "redis"})
+	assert.NoError(t, err)
+	assert.Empty(t, services)
+
+	assert.Eventually(t, func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+
+		return len(cache.entries) == 0
+	}, time.Second, 5*time.Millisecond)
+}
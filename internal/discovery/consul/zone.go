@@ -0,0 +1,20 @@
+package consul
+
+import capi "github.com/hashicorp/consul/api"
+
+// inZone returns the instances located on nodes in the zone, the zone of a node is
+// defined by its metadata. All instances are returned if the zone isn't set.
+func inZone(services []*capi.ServiceEntry, key, zone string) []*capi.ServiceEntry {
+	if zone == "" {
+		return services
+	}
+
+	filtered := make([]*capi.ServiceEntry, 0, len(services))
+	for _, service := range services {
+		if service.Node != nil && service.Node.Meta[key] == zone {
+			filtered = append(filtered, service)
+		}
+	}
+
+	return filtered
+}
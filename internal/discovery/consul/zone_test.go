@@ -0,0 +1,24 @@
+package consul
+
+import (
+	"testing"
+
+	capi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInZone(t *testing.T) {
+	t.Parallel()
+
+	services := []*capi.ServiceEntry{
+		{Node: &capi.Node{Node: "node-a", Meta: map[string]string{"zone": "zone-a"}}},
+		{Node: &capi.Node{Node: "node-b", Meta: map[string]string{"zone": "zone-b"}}},
+		{Node: &capi.Node{Node: "node-c"}},
+		{},
+	}
+
+	assert.Equal(t, services, inZone(services, "zone", ""))
+	assert.Equal(t, services[:1], inZone(services, "zone", "zone-a"))
+	assert.Equal(t, services[1:2], inZone(services, "zone", "zone-b"))
+	assert.Empty(t, inZone(services, "availability-zone", "zone-a"))
+}
@@ -7,20 +7,46 @@ import (
 
 const (
 	Kubernetes = "kubernetes"
-	Consul     = "consul"
+	// KubernetesSelector evaluates pods selected by a label selector.
+	KubernetesSelector = "kubernetes-selector"
+	// KubernetesWorkload evaluates the rollout state of workloads.
+	KubernetesWorkload = "kubernetes-workload"
+	// KubernetesRoute evaluates backend services of Ingresses and HTTPRoutes.
+	KubernetesRoute = "kubernetes-route"
+	Consul          = "consul"
+	// ConsulQuery evaluates Consul prepared queries.
+	ConsulQuery = "consul-query"
 )
 
 func New(discovery *Discovery) (Adapter, error) {
+	clients := discovery.Kubernetes
+	if clients == nil {
+		clients = k8s.NewClients(discovery.Logger, discovery.Config, discovery.Metrics)
+	}
+
 	switch discovery.Source {
 	case Kubernetes:
-		return k8s.New(&k8s.Client{
-			Logger: discovery.Logger,
-			Config: discovery.Config,
-		})
+		if discovery.Clusters {
+			return k8s.NewClusters(clients)
+		}
+		return clients.Get("")
+	case KubernetesSelector:
+		return k8s.NewSelector(clients)
+	case KubernetesWorkload:
+		return k8s.NewWorkload(clients, discovery.Kind)
+	case KubernetesRoute:
+		return k8s.NewRoute(clients, discovery.Kind)
 	case Consul:
 		return consul.New(&consul.Client{
-			Logger: discovery.Logger,
-			Config: discovery.Config,
+			Logger:  discovery.Logger,
+			Config:  discovery.Config,
+			Metrics: discovery.Metrics,
+		})
+	case ConsulQuery:
+		return consul.NewPreparedQuery(&consul.Client{
+			Logger:  discovery.Logger,
+			Config:  discovery.Config,
+			Metrics: discovery.Metrics,
 		})
 	default:
 		return nil, nil
@@ -0,0 +1,54 @@
+package endpoints
+
+import "github.com/gofiber/fiber/v2"
+
+// localsKey is the key of the status in the request context.
+const localsKey = "healthgroup.endpoints"
+
+// Status represents the endpoints of a service reported by the discovery service
+// while evaluating its health.
+type Status struct {
+	Ready       int
+	NotReady    int
+	Terminating int
+	// Warning is the number of instances with checks in the warning state, they are
+	// counted either as ready or not ready.
+	Warning int
+	// Required is the minimum number of ready endpoints for the service to be healthy.
+	Required int
+	// Updated is the number of replicas of a workload that run the latest version,
+	// at least RequiredUpdated of them are required for the workload to be healthy.
+	Updated         int
+	RequiredUpdated int
+	// Datacenter is the datacenter the endpoints belong to, if the discovery service
+	// evaluated more than one.
+	Datacenter string
+	// Node is set if only the endpoints located on the node were counted.
+	Node string
+	// Zone is set if only the endpoints located in the zone were counted.
+	Zone string
+	// Backends are the services a route forwards traffic to. Each of them is evaluated
+	// on its own, the status of the route counts healthy backends as ready.
+	Backends []Backend
+}
+
+// Backend represents a backend service of a route.
+type Backend struct {
+	Namespace string
+	Service   string
+	Port      string
+	Exists    bool
+	Healthy   bool
+	Status    Status
+}
+
+// Set stores the status in the request context.
+func Set(c *fiber.Ctx, status Status) {
+	c.Locals(localsKey, status)
+}
+
+// Get returns the status stored in the request context, if any.
+func Get(c *fiber.Ctx) (Status, bool) {
+	status, ok := c.Locals(localsKey).(Status)
+	return status, ok
+}
@@ -3,25 +3,40 @@ package k8s
 import (
 	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
+	"github.com/tczekajlo/healthgroup/internal/quorum"
 	"go.uber.org/zap"
 	"golang.org/x/xerrors"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
+// source is used to label metrics of the Kubernetes API requests.
+const source = "kubernetes"
+
 type Client struct {
-	Logger *zap.Logger
-	Config *config.Config
+	Logger  *zap.Logger
+	Config  *config.Config
+	Metrics *metrics.Metrics
+	// Cluster is the name of the configured cluster, the cluster given by flags is
+	// used if empty.
+	Cluster string
 
-	clientset  *kubernetes.Clientset
+	clientset  kubernetes.Interface
+	dynamic    dynamic.Interface
 	httpClient *http.Client
+	cache      *watchCache
+	zones      zoneCache
 }
 
 func New(c *Client) (*Client, error) {
@@ -29,23 +44,9 @@ func New(c *Client) (*Client, error) {
 		return nil, xerrors.New("Kubernetes client is disabled. You can enabled it in the configuration file")
 	}
 
-	var (
-		config *rest.Config
-		err    error
-	)
-
-	flags := c.Config.Flags()
-
-	if flags.InCluster {
-		config, err = rest.InClusterConfig()
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		config, err = clientcmd.BuildConfigFromFlags("", flags.Kubeconfig)
-		if err != nil {
-			return nil, err
-		}
+	config, err := c.restConfig()
+	if err != nil {
+		return nil, err
 	}
 	// creates the clientset
 	c.httpClient, err = rest.HTTPClientFor(config)
@@ -59,7 +60,19 @@ func New(c *Client) (*Client, error) {
 	}
 	c.clientset = clientset
 
-	c.Logger.Debug("new Kubernetes client has been initialized")
+	// The dynamic client reads resources that have no typed client, e.g. Gateway API routes.
+	c.dynamic, err = dynamic.NewForConfigAndClient(config, c.httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Config.Kubernetes.Informers {
+		if err := c.startInformers(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.Logger.Debug("new Kubernetes client has been initialized", zap.String("cluster", c.Cluster))
 	return c, nil
 }
 
@@ -68,7 +81,8 @@ func (c *Client) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
 	service := ctx.Params("service")
 	requestID := ctx.GetRespHeader("X-Request-Id")
 
-	_, err := c.clientset.CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+	_, err := c.getService(namespace, service)
+
 	if errors.IsNotFound(err) {
 		c.Logger.Debug("Kubernetes service doesn't exist",
 			zap.String("request_id", requestID),
@@ -83,40 +97,216 @@ func (c *Client) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
 	return true, nil
 }
 
+func (c *Client) getService(namespace, service string) (*v1.Service, error) {
+	if c.cache != nil {
+		return c.cache.getService(namespace, service)
+	}
+
+	start := time.Now()
+	svc, err := c.clientset.CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+	c.Metrics.ObserveDiscovery(source, "get_service", start, ignoreNotFound(err))
+
+	return svc, err
+}
+
 func (c *Client) GetEndpoints(namespace, service string) (*v1.Endpoints, error) {
-	return c.clientset.CoreV1().Endpoints(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+	if c.cache != nil {
+		return c.cache.getEndpoints(namespace, service)
+	}
+
+	start := time.Now()
+	endpoints, err := c.clientset.CoreV1().Endpoints(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+	c.Metrics.ObserveDiscovery(source, "get_endpoints", start, err)
+
+	return endpoints, err
 }
 
 func (c *Client) IsServiceHealthy(ctx *fiber.Ctx) (bool, error) {
 	namespace := ctx.Params("namespace")
 	service := ctx.Params("service")
-	requestID := ctx.GetRespHeader("X-Request-Id")
 
-	svcExists, err := c.IsServiceExists(ctx)
+	l, err := c.newLocality(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	p, err := c.newProbe(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	status, exists, err := c.serviceStatus(ctx, namespace, service, ctx.Query("port"), l, p)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	return c.evaluate(ctx, service, l, status)
+}
+
+// serviceStatus returns the status of endpoints of the service exposing the port, or
+// false if the service doesn't exist.
+func (c *Client) serviceStatus(ctx *fiber.Ctx, namespace, service, port string, l locality, p targetProbe) (endpoints.Status, bool, error) {
+	svc, err := c.getService(namespace, service)
+	if errors.IsNotFound(err) {
+		return endpoints.Status{}, false, nil
+	} else if err != nil {
+		return endpoints.Status{}, false, err
+	}
+
+	var (
+		pf     = newPortFilter(port, svc)
+		status endpoints.Status
+	)
+
+	switch {
+	case svc.Spec.Type == v1.ServiceTypeExternalName:
+		// ExternalName services have no endpoints, the external name is checked instead.
+		status, err = c.externalNameStatus(ctx, svc, p, pf)
+	case len(svc.Spec.Selector) == 0 && p.enabled():
+		// Addresses of manually managed endpoints have no readiness, they are probed instead.
+		status, err = c.selectorlessStatus(ctx, svc, l, p, pf)
+	default:
+		status, err = c.endpointsStatus(namespace, service, l, pf)
+	}
+
+	return status, true, err
+}
+
+// selectorlessStatus probes addresses of the manually managed Endpoints of the service.
+// EndpointSlices aren't used, they are only mirrored from the Endpoints.
+func (c *Client) selectorlessStatus(ctx *fiber.Ctx, svc *v1.Service, l locality, p targetProbe, pf portFilter) (endpoints.Status, error) {
+	endpoint, err := c.GetEndpoints(svc.Namespace, svc.Name)
+	if errors.IsNotFound(err) {
+		return endpoints.Status{}, nil
+	} else if err != nil {
+		return endpoints.Status{}, err
+	}
+	endpoint = pf.endpoints(endpoint)
+
+	if err := c.resolveZones(&l, addressNodes(endpoint)); err != nil {
+		return endpoints.Status{}, err
+	}
+
+	return c.probeAddresses(ctx, endpoint, l, p, pf)
+}
+
+// evaluate applies the minimum of ready endpoints to the status of endpoints and
+// stores the status in the request context.
+func (c *Client) evaluate(ctx *fiber.Ctx, name string, l locality, status endpoints.Status) (bool, error) {
+	var err error
+
+	status.Node = l.node
+	status.Zone = l.zone
+
+	status.Required, err = minReady(ctx.Query("minReady", c.Config.Kubernetes.MinReady), status.Ready+status.NotReady)
 	if err != nil {
 		return false, err
 	}
+	endpoints.Set(ctx, status)
+
+	healthy := status.Ready >= status.Required
+
+	c.Logger.Debug("Kubernetes service endpoints",
+		zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+		zap.String("namespace", ctx.Params("namespace")),
+		zap.String("service", name),
+		zap.String("node", l.node),
+		zap.String("zone", l.zone),
+		zap.Int("ready", status.Ready),
+		zap.Int("not_ready", status.NotReady),
+		zap.Int("terminating", status.Terminating),
+		zap.Int("required", status.Required),
+		zap.Bool("healthy", healthy),
+	)
 
-	if svcExists {
+	return healthy, nil
+}
+
+// endpointsStatus counts endpoints of the service using EndpointSlices, or the legacy
+// Endpoints API if EndpointSlices are disabled. Only endpoints within the locality and
+// exposing the port are counted, the legacy API has no zone information so the zone
+// of nodes is used.
+func (c *Client) endpointsStatus(namespace, service string, l locality, p portFilter) (endpoints.Status, error) {
+	if !c.Config.Kubernetes.EndpointSlices {
 		endpoint, err := c.GetEndpoints(namespace, service)
 		if err != nil {
-			return false, err
+			return endpoints.Status{}, err
 		}
+		endpoint = p.endpoints(endpoint)
 
-		if len(endpoint.Subsets) > 0 {
-			c.Logger.Debug("Kubernetes service is healthy",
-				zap.String("request_id", requestID),
-				zap.String("namespace", namespace),
-				zap.String("service", service),
-				zap.Any("endpoint_subnets", endpoint.Subsets),
-			)
-			return true, nil
+		if err := c.resolveZones(&l, addressNodes(endpoint)); err != nil {
+			return endpoints.Status{}, err
 		}
+
+		return countAddresses(endpoint, l), nil
+	}
+
+	slices, err := c.GetEndpointSlices(namespace, service)
+	if err != nil {
+		return endpoints.Status{}, err
+	}
+	slices = p.slices(slices)
+
+	if err := c.resolveZones(&l, sliceNodes(slices)); err != nil {
+		return endpoints.Status{}, err
+	}
+
+	return countEndpoints(slices, c.Config.Kubernetes.AddressType, c.Config.Kubernetes.Condition, l), nil
+}
+
+// countAddresses returns the number of ready and not ready addresses of all subsets
+// within the locality.
+func countAddresses(endpoint *v1.Endpoints, l locality) endpoints.Status {
+	var status endpoints.Status
+
+	for _, subset := range endpoint.Subsets {
+		for _, address := range subset.Addresses {
+			if l.contains(address.NodeName, nil) {
+				status.Ready++
+			}
+		}
+
+		for _, address := range subset.NotReadyAddresses {
+			if l.contains(address.NodeName, nil) {
+				status.NotReady++
+			}
+		}
+	}
+
+	return status
+}
+
+// minReady returns the minimum number of ready addresses out of total. The value is
+// either a number or a percentage of total, at least one address is required by default.
+func minReady(value string, total int) (int, error) {
+	if strings.TrimSpace(value) == "" {
+		return 1, nil
+	}
+
+	required, err := quorum.Required(value, total)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid minimum of ready endpoints, minReady: %s: %w", value, err)
+	}
+
+	// A percentage of no endpoints shouldn't make the service healthy.
+	if quorum.IsPercentage(value) && required < 1 {
+		return 1, nil
+	}
+
+	return required, nil
+}
+
+// ignoreNotFound returns nil if the error reports that a resource doesn't exist.
+func ignoreNotFound(err error) error {
+	if errors.IsNotFound(err) {
+		return nil
 	}
 
-	return false, nil
+	return err
 }
 
+// Close releases idle connections. Informers aren't stopped, they keep the cache
+// up to date for as long as the process runs.
 func (c *Client) Close() {
 	c.httpClient.CloseIdleConnections()
 }
@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestCountAddresses(t *testing.T) {
+	t.Parallel()
+
+	nodeA, nodeB := "node-a", "node-b"
+
+	endpoint := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses:         []v1.EndpointAddress{{IP: "10.0.0.1", NodeName: &nodeA}, {IP: "10.0.0.2", NodeName: &nodeB}},
+				NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.3", NodeName: &nodeA}},
+			},
+			{
+				NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.4"}},
+			},
+		},
+	}
+
+	assert.Equal(t, endpoints.Status{Ready: 2, NotReady: 2}, countAddresses(endpoint, locality{}))
+	assert.Equal(t, endpoints.Status{Ready: 1, NotReady: 1}, countAddresses(endpoint, locality{node: nodeA}))
+	assert.Equal(t, endpoints.Status{Ready: 1}, countAddresses(endpoint, locality{zone: "zone-b", nodeZones: map[string]string{nodeB: "zone-b"}}))
+	assert.Equal(t, endpoints.Status{}, countAddresses(&v1.Endpoints{}, locality{}))
+}
+
+func TestMinReady(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		value    string
+		total    int
+		expected int
+		err      bool
+	}{
+		{value: "", total: 4, expected: 1},
+		{value: "3", total: 4, expected: 3},
+		{value: "0", total: 4, expected: 0},
+		{value: "50%", total: 4, expected: 2},
+		{value: "50%", total: 0, expected: 1},
+		{value: "ten", total: 4, err: true},
+	}
+
+	for _, item := range table {
+		required, err := minReady(item.value, item.total)
+		if item.err {
+			assert.Error(t, err, item.value)
+			continue
+		}
+
+		assert.NoError(t, err, item.value)
+		assert.Equal(t, item.expected, required, item.value)
+	}
+}
@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"sync"
+
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// Clients keeps one client per cluster, so all adapters share the same connections
+// and informers of a cluster.
+type Clients struct {
+	Logger  *zap.Logger
+	Config  *config.Config
+	Metrics *metrics.Metrics
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	errs    map[string]error
+}
+
+func NewClients(logger *zap.Logger, config *config.Config, metrics *metrics.Metrics) *Clients {
+	return &Clients{
+		Logger:  logger,
+		Config:  config,
+		Metrics: metrics,
+		clients: map[string]*Client{},
+		errs:    map[string]error{},
+	}
+}
+
+// Get returns the client of the configured cluster, or of the cluster given by flags
+// if the cluster is empty. The client is initialized on the first call, an error of
+// the initialization is kept, so caches of a cluster are synced at most once.
+func (c *Clients) Get(cluster string) (*Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[cluster]; ok {
+		return client, nil
+	}
+	if err, ok := c.errs[cluster]; ok {
+		return nil, err
+	}
+
+	client, err := New(&Client{
+		Logger:  c.Logger,
+		Config:  c.Config,
+		Metrics: c.Metrics,
+		Cluster: cluster,
+	})
+	if err != nil {
+		c.errs[cluster] = err
+		return nil, err
+	}
+	c.clients[cluster] = client
+
+	return client, nil
+}
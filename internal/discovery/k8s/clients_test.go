@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/log"
+)
+
+func TestClients(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger), config.WithFlags(&config.Flags{}))
+	assert.NoError(t, c.SetDefault())
+
+	c.Kubernetes.Clusters = []config.KubernetesCluster{
+		{Name: "eu-west", Server: "https://eu-west.example.com:6443"},
+		{Name: "us-east", Server: "https://us-east.example.com:6443"},
+	}
+	clients := NewClients(logger, c, nil)
+
+	client, err := clients.Get("eu-west")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west", client.Cluster)
+
+	// The client is shared by all adapters of the cluster.
+	shared, err := clients.Get("eu-west")
+	assert.NoError(t, err)
+	assert.Same(t, client, shared)
+
+	other, err := clients.Get("us-east")
+	assert.NoError(t, err)
+	assert.NotSame(t, client, other)
+
+	_, err = clients.Get("ap-south")
+	assert.Error(t, err)
+
+	// A failed initialization isn't repeated.
+	_, cached := clients.Get("ap-south")
+	assert.Same(t, err, cached)
+}
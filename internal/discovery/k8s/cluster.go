@@ -0,0 +1,138 @@
+package k8s
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// restConfig returns the configuration of the cluster of the client.
+func (c *Client) restConfig() (*rest.Config, error) {
+	flags := c.Config.Flags()
+
+	if c.Cluster == "" {
+		if flags.InCluster {
+			return rest.InClusterConfig()
+		}
+
+		return clientcmd.BuildConfigFromFlags("", flags.Kubeconfig)
+	}
+
+	for _, cluster := range c.Config.Kubernetes.Clusters {
+		if cluster.Name == c.Cluster {
+			return clusterConfig(cluster, flags.Kubeconfig)
+		}
+	}
+
+	return nil, xerrors.Errorf("Kubernetes cluster isn't configured, cluster: %s", c.Cluster)
+}
+
+// clusterConfig returns the configuration of a configured cluster. The kubeconfig given
+// by flags is used if the cluster doesn't define one.
+func clusterConfig(cluster config.KubernetesCluster, kubeconfig string) (*rest.Config, error) {
+	switch {
+	case cluster.InCluster:
+		return rest.InClusterConfig()
+	case cluster.Server != "":
+		return &rest.Config{
+			Host:            cluster.Server,
+			BearerToken:     cluster.Token,
+			BearerTokenFile: cluster.TokenFile,
+			TLSClientConfig: rest.TLSClientConfig{
+				CAFile: cluster.CAFile,
+			},
+		}, nil
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cluster.Kubeconfig != "" {
+		rules.ExplicitPath = cluster.Kubeconfig
+	} else if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{
+		CurrentContext: cluster.Context,
+	}).ClientConfig()
+}
+
+// Clusters evaluates services of the cluster given by the cluster path parameter.
+type Clusters struct {
+	Logger  *zap.Logger
+	clients map[string]*Client
+}
+
+// NewClusters returns the adapter of all configured clusters, it shares the client of
+// each of them.
+func NewClusters(c *Clients) (*Clusters, error) {
+	if !c.Config.Kubernetes.Enabled {
+		return nil, xerrors.New("Kubernetes client is disabled. You can enabled it in the configuration file")
+	}
+
+	clusters := &Clusters{
+		Logger:  c.Logger,
+		clients: map[string]*Client{},
+	}
+
+	for _, cluster := range c.Config.Kubernetes.Clusters {
+		if cluster.Name == "" {
+			clusters.Close()
+			return nil, xerrors.New("name of Kubernetes cluster is required")
+		}
+		if _, ok := clusters.clients[cluster.Name]; ok {
+			clusters.Close()
+			return nil, xerrors.Errorf("Kubernetes cluster is defined more than once, cluster: %s", cluster.Name)
+		}
+
+		client, err := c.Get(cluster.Name)
+		if err != nil {
+			clusters.Close()
+			return nil, xerrors.Errorf("unable to initialize Kubernetes client, cluster: %s: %w", cluster.Name, err)
+		}
+		clusters.clients[cluster.Name] = client
+	}
+
+	return clusters, nil
+}
+
+// IsServiceExists returns false if the cluster isn't configured.
+func (c *Clusters) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
+	client, ok := c.client(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	return client.IsServiceExists(ctx)
+}
+
+func (c *Clusters) IsServiceHealthy(ctx *fiber.Ctx) (bool, error) {
+	client, ok := c.client(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	return client.IsServiceHealthy(ctx)
+}
+
+func (c *Clusters) client(ctx *fiber.Ctx) (*Client, bool) {
+	cluster := ctx.Params("cluster")
+
+	client, ok := c.clients[cluster]
+	if !ok {
+		c.Logger.Debug("Kubernetes cluster isn't configured",
+			zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+			zap.String("cluster", cluster),
+		)
+	}
+
+	return client, ok
+}
+
+func (c *Clusters) Close() {
+	for _, client := range c.clients {
+		client.Close()
+	}
+}
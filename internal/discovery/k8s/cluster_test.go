@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+current-context: eu-west
+clusters:
+- name: eu-west
+  cluster:
+    server: https://eu-west.example.com:6443
+- name: us-east
+  cluster:
+    server: https://us-east.example.com:6443
+contexts:
+- name: eu-west
+  context:
+    cluster: eu-west
+    user: healthgroup
+- name: us-east
+  context:
+    cluster: us-east
+    user: healthgroup
+users:
+- name: healthgroup
+  user:
+    token: secret
+`
+
+func TestClusterConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(path, []byte(kubeconfig), 0o600))
+
+	table := []struct {
+		desc       string
+		cluster    config.KubernetesCluster
+		kubeconfig string
+		host       string
+	}{
+		{
+			desc:    "server and token",
+			cluster: config.KubernetesCluster{Name: "eu-west", Server: "https://10.0.0.1:6443", Token: "secret", CAFile: "/ca.crt"},
+			host:    "https://10.0.0.1:6443",
+		},
+		{
+			desc:    "kubeconfig context",
+			cluster: config.KubernetesCluster{Name: "us-east", Kubeconfig: path, Context: "us-east"},
+			host:    "https://us-east.example.com:6443",
+		},
+		{
+			desc:    "kubeconfig current context",
+			cluster: config.KubernetesCluster{Name: "eu-west", Kubeconfig: path},
+			host:    "https://eu-west.example.com:6443",
+		},
+		{
+			desc:       "kubeconfig given by flags",
+			cluster:    config.KubernetesCluster{Name: "us-east", Context: "us-east"},
+			kubeconfig: path,
+			host:       "https://us-east.example.com:6443",
+		},
+	}
+
+	for _, item := range table {
+		cfg, err := clusterConfig(item.cluster, item.kubeconfig)
+		assert.NoError(t, err, item.desc)
+		assert.Equal(t, item.host, cfg.Host, item.desc)
+		assert.Equal(t, "secret", cfg.BearerToken, item.desc)
+	}
+
+	_, err := clusterConfig(config.KubernetesCluster{Name: "ap-south", Kubeconfig: path, Context: "ap-south"}, "")
+	assert.Error(t, err)
+}
+
+func TestNewClusters(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger), config.WithFlags(&config.Flags{}))
+	assert.NoError(t, c.SetDefault())
+
+	c.Kubernetes.Clusters = []config.KubernetesCluster{
+		{Name: "eu-west", Server: "https://eu-west.example.com:6443"},
+		{Name: "us-east", Server: "https://us-east.example.com:6443"},
+	}
+	clusters, err := NewClusters(NewClients(logger, c, nil))
+	assert.NoError(t, err)
+	assert.Len(t, clusters.clients, 2)
+	assert.Equal(t, "us-east", clusters.clients["us-east"].Cluster)
+
+	c.Kubernetes.Clusters = append(c.Kubernetes.Clusters, config.KubernetesCluster{Name: "eu-west"})
+	_, err = NewClusters(NewClients(logger, c, nil))
+	assert.Error(t, err)
+
+	c.Kubernetes.Clusters = []config.KubernetesCluster{{Server: "https://eu-west.example.com:6443"}}
+	_, err = NewClusters(NewClients(logger, c, nil))
+	assert.Error(t, err)
+}
+
+func TestClusters(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	assert.NoError(t, c.SetDefault())
+
+	clusters := &Clusters{
+		Logger: logger,
+		clients: map[string]*Client{
+			"eu-west": {
+				Logger: logger,
+				Config: c,
+				clientset: fake.NewSimpleClientset(
+					&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}},
+				),
+			},
+			"us-east": {
+				Logger:    logger,
+				Config:    c,
+				clientset: fake.NewSimpleClientset(),
+			},
+		},
+	}
+
+	table := []struct {
+		path   string
+		exists bool
+	}{
+		{path: "/eu-west/default/api", exists: true},
+		{path: "/us-east/default/api", exists: false},
+		{path: "/ap-south/default/api", exists: false},
+	}
+
+	for _, item := range table {
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+		})
+		app.Get("/:cluster/:namespace/:service", func(ctx *fiber.Ctx) error {
+			exists, err := clusters.IsServiceExists(ctx)
+			assert.NoError(t, err, item.path)
+			assert.Equal(t, item.exists, exists, item.path)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost"+item.path, nil))
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReady counts endpoints that are ready, terminating endpoints are never ready.
+	ConditionReady = "ready"
+	// ConditionServing counts endpoints that are serving, including terminating ones.
+	ConditionServing = "serving"
+)
+
+// GetEndpointSlices returns all EndpointSlices of the service.
+func (c *Client) GetEndpointSlices(namespace, service string) ([]discoveryv1.EndpointSlice, error) {
+	if c.cache != nil {
+		return c.cache.listEndpointSlices(namespace, service)
+	}
+
+	start := time.Now()
+	slices, err := c.clientset.DiscoveryV1().EndpointSlices(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service,
+	})
+	c.Metrics.ObserveDiscovery(source, "list_endpoint_slices", start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Items, nil
+}
+
+// countEndpoints returns the number of ready, not ready and terminating endpoints of
+// all slices of the given address type, or of all types if it's empty. An endpoint
+// that is present in slices of both IPv4 and IPv6 address families is counted once.
+// Only endpoints within the locality are counted.
+func countEndpoints(slices []discoveryv1.EndpointSlice, addressType, condition string, l locality) endpoints.Status {
+	var status endpoints.Status
+
+	seen := map[string]bool{}
+
+	for _, slice := range slices {
+		if addressType != "" && !strings.EqualFold(string(slice.AddressType), addressType) {
+			continue
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			if !l.contains(endpoint.NodeName, endpoint.Zone) {
+				continue
+			}
+
+			key := endpointKey(slice.AddressType, endpoint)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			terminating := isTrue(endpoint.Conditions.Terminating, false)
+			if terminating {
+				status.Terminating++
+			}
+
+			// A nil condition should be interpreted as ready, serving defers to ready.
+			ready := isTrue(endpoint.Conditions.Ready, true)
+			if condition == ConditionServing {
+				ready = isTrue(endpoint.Conditions.Serving, ready)
+			}
+
+			switch {
+			case ready:
+				status.Ready++
+			case !terminating:
+				status.NotReady++
+			}
+		}
+	}
+
+	return status
+}
+
+// endpointKey identifies the endpoint across slices. Endpoints backed by the same
+// object, e.g. a pod, are the same endpoint regardless of the address family.
+func endpointKey(addressType discoveryv1.AddressType, endpoint discoveryv1.Endpoint) string {
+	if ref := endpoint.TargetRef; ref != nil {
+		return strings.Join([]string{ref.Kind, ref.Namespace, ref.Name, string(ref.UID)}, "/")
+	}
+
+	return string(addressType) + "/" + strings.Join(endpoint.Addresses, ",")
+}
+
+func isTrue(condition *bool, defaultValue bool) bool {
+	if condition == nil {
+		return defaultValue
+	}
+
+	return *condition
+}
@@ -0,0 +1,93 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+func TestCountEndpoints(t *testing.T) {
+	t.Parallel()
+
+	yes, no := true, false
+	nodeA, nodeB := "node-a", "node-b"
+	zoneA := "zone-a"
+	pod := func(name string) *v1.ObjectReference {
+		return &v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: name}
+	}
+
+	slices := []discoveryv1.EndpointSlice{
+		{
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, TargetRef: pod("api-1"), NodeName: &nodeA, Zone: &zoneA, Conditions: discoveryv1.EndpointConditions{Ready: &yes}},
+				{Addresses: []string{"10.0.0.2"}, TargetRef: pod("api-2"), NodeName: &nodeB},
+				{Addresses: []string{"10.0.0.3"}, TargetRef: pod("api-3"), NodeName: &nodeA, Conditions: discoveryv1.EndpointConditions{Ready: &no}},
+				{
+					Addresses:  []string{"10.0.0.4"},
+					TargetRef:  pod("api-4"),
+					NodeName:   &nodeA,
+					Conditions: discoveryv1.EndpointConditions{Ready: &no, Serving: &yes, Terminating: &yes},
+				},
+			},
+		},
+		{
+			// The same pods in the IPv6 address family.
+			AddressType: discoveryv1.AddressTypeIPv6,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"fd00::1"}, TargetRef: pod("api-1"), NodeName: &nodeA, Zone: &zoneA, Conditions: discoveryv1.EndpointConditions{Ready: &yes}},
+				{Addresses: []string{"fd00::2"}, TargetRef: pod("api-2"), NodeName: &nodeB},
+			},
+		},
+	}
+
+	table := []struct {
+		desc        string
+		addressType string
+		condition   string
+		locality    locality
+		expected    endpoints.Status
+	}{
+		{
+			desc:      "ready",
+			condition: ConditionReady,
+			expected:  endpoints.Status{Ready: 2, NotReady: 1, Terminating: 1},
+		},
+		{
+			desc:      "serving",
+			condition: ConditionServing,
+			expected:  endpoints.Status{Ready: 3, NotReady: 1, Terminating: 1},
+		},
+		{
+			desc:        "IPv6 only",
+			addressType: "ipv6",
+			condition:   ConditionReady,
+			expected:    endpoints.Status{Ready: 2},
+		},
+		{
+			desc:      "node-local",
+			condition: ConditionReady,
+			locality:  locality{node: nodeA},
+			expected:  endpoints.Status{Ready: 1, NotReady: 1, Terminating: 1},
+		},
+		{
+			desc:      "node without endpoints",
+			condition: ConditionReady,
+			locality:  locality{node: "node-c"},
+			expected:  endpoints.Status{},
+		},
+		{
+			desc:      "zone",
+			condition: ConditionReady,
+			locality:  locality{zone: zoneA, nodeZones: map[string]string{nodeA: "zone-b", nodeB: zoneA}},
+			expected:  endpoints.Status{Ready: 2},
+		},
+	}
+
+	for _, item := range table {
+		assert.Equal(t, item.expected, countEndpoints(slices, item.addressType, item.condition, item.locality), item.desc)
+	}
+}
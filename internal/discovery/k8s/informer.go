@@ -0,0 +1,138 @@
+package k8s
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+)
+
+// watchCache answers requests from the local cache of shared informers, one
+// informer factory is started for each watched namespace.
+type watchCache struct {
+	factories map[string]informers.SharedInformerFactory
+	stop      chan struct{}
+}
+
+// startInformers starts informers of services and endpoints, and waits until their
+// caches are synced.
+func (c *Client) startInformers() error {
+	cfg := c.Config.Kubernetes
+
+	namespaces := cfg.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	c.cache = &watchCache{
+		factories: map[string]informers.SharedInformerFactory{},
+		stop:      make(chan struct{}),
+	}
+
+	for _, namespace := range namespaces {
+		factory := informers.NewSharedInformerFactoryWithOptions(c.clientset, 0,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = cfg.LabelSelector
+			}),
+		)
+
+		// Informers have to be requested before the factory is started. Endpoints are
+		// watched even if EndpointSlices are used, as Endpoints of services without
+		// a selector are probed.
+		factory.Core().V1().Services().Informer()
+		factory.Core().V1().Endpoints().Informer()
+		if cfg.EndpointSlices {
+			factory.Discovery().V1().EndpointSlices().Informer()
+		}
+
+		factory.Start(c.cache.stop)
+		c.cache.factories[namespace] = factory
+	}
+
+	timeout := make(chan struct{})
+	timer := time.AfterFunc(cfg.SyncTimeout, func() { close(timeout) })
+	defer timer.Stop()
+
+	for namespace, factory := range c.cache.factories {
+		for informer, synced := range factory.WaitForCacheSync(timeout) {
+			if !synced {
+				c.cache.close()
+				return xerrors.Errorf("Kubernetes cache hasn't been synced within %s, namespace: %q, informer: %s",
+					cfg.SyncTimeout, namespace, informer)
+			}
+		}
+	}
+
+	c.Logger.Info("Kubernetes cache has been synced",
+		zap.Strings("namespaces", cfg.Namespaces),
+		zap.String("label_selector", cfg.LabelSelector),
+	)
+
+	return nil
+}
+
+// factory returns the informer factory that watches the namespace.
+func (w *watchCache) factory(namespace string) (informers.SharedInformerFactory, error) {
+	if factory, ok := w.factories[metav1.NamespaceAll]; ok {
+		return factory, nil
+	}
+
+	if factory, ok := w.factories[namespace]; ok {
+		return factory, nil
+	}
+
+	return nil, xerrors.Errorf("namespace isn't watched, namespace: %s", namespace)
+}
+
+func (w *watchCache) getService(namespace, service string) (*v1.Service, error) {
+	factory, err := w.factory(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.Core().V1().Services().Lister().Services(namespace).Get(service)
+}
+
+func (w *watchCache) getEndpoints(namespace, service string) (*v1.Endpoints, error) {
+	factory, err := w.factory(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.Core().V1().Endpoints().Lister().Endpoints(namespace).Get(service)
+}
+
+func (w *watchCache) listEndpointSlices(namespace, service string) ([]discoveryv1.EndpointSlice, error) {
+	factory, err := w.factory(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: service})
+
+	slices, err := factory.Discovery().V1().EndpointSlices().Lister().EndpointSlices(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]discoveryv1.EndpointSlice, 0, len(slices))
+	for _, slice := range slices {
+		items = append(items, *slice)
+	}
+
+	return items, nil
+}
+
+func (w *watchCache) close() {
+	close(w.stop)
+
+	for _, factory := range w.factories {
+		factory.Shutdown()
+	}
+}
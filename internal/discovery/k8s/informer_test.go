@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInformers(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+	c.Kubernetes.Informers = true
+	c.Kubernetes.Namespaces = []string{"default"}
+
+	open, _ := newListener(t)
+
+	ready := true
+	client := &Client{
+		Logger: logger,
+		Config: c,
+		clientset: fake.NewSimpleClientset(
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}},
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "worker"}},
+			&discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "api-abcde",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "api"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+				},
+			},
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "manual"}},
+			&v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "manual"},
+				Subsets: []v1.EndpointSubset{
+					{
+						Addresses: []v1.EndpointAddress{{IP: "127.0.0.1"}},
+						Ports:     []v1.EndpointPort{{Port: open}},
+					},
+				},
+			},
+		),
+	}
+
+	err := client.startInformers()
+	assert.NoError(t, err)
+	defer client.cache.close()
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/:namespace/:service", func(ctx *fiber.Ctx) error {
+		healthy, err := client.IsServiceHealthy(ctx)
+		if err != nil {
+			return ctx.SendStatus(fiber.StatusServiceUnavailable)
+		}
+		if !healthy {
+			return ctx.SendStatus(fiber.StatusNotFound)
+		}
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	table := []struct {
+		path     string
+		expected int
+	}{
+		{path: "/default/api", expected: fiber.StatusOK},
+		{path: "/default/worker", expected: fiber.StatusNotFound},
+		{path: "/default/missing", expected: fiber.StatusNotFound},
+		// Endpoints of services without a selector are watched along with EndpointSlices.
+		{path: "/default/manual?probe=tcp", expected: fiber.StatusOK},
+		{path: "/kube-system/api", expected: fiber.StatusServiceUnavailable},
+	}
+
+	for _, item := range table {
+		resp, _ := app.Test(httptest.NewRequest("GET", "http://localhost"+item.path, nil), int(time.Second.Milliseconds()))
+		assert.Equal(t, item.expected, resp.StatusCode, item.path)
+	}
+}
@@ -0,0 +1,197 @@
+package k8s
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodeZoneTTL is how long the zone of a node is cached, topology labels of nodes
+// rarely change.
+const nodeZoneTTL = 5 * time.Minute
+
+// locality restricts the endpoints that are counted to a node and/or a zone.
+type locality struct {
+	node string
+	zone string
+	// nodeZones is the zone of nodes, used for endpoints without zone information.
+	nodeZones map[string]string
+}
+
+// newLocality returns the locality of the request.
+func (c *Client) newLocality(ctx *fiber.Ctx) (locality, error) {
+	node, err := c.nodeLocal(ctx)
+	if err != nil {
+		return locality{}, err
+	}
+
+	return locality{node: node, zone: ctx.Query("zone")}, nil
+}
+
+// nodeLocal returns the node whose endpoints are counted if the node-local mode is
+// enabled, or an empty string if all endpoints are counted. The mode is enabled by
+// the configuration or the local query parameter.
+func (c *Client) nodeLocal(ctx *fiber.Ctx) (string, error) {
+	local := c.Config.Kubernetes.NodeLocal
+
+	if v := ctx.Query("local"); v != "" {
+		var err error
+		local, err = strconv.ParseBool(v)
+		if err != nil {
+			return "", xerrors.Errorf("invalid value, local: %s", v)
+		}
+	}
+
+	if !local {
+		return "", nil
+	}
+
+	if c.Config.Kubernetes.NodeName == "" {
+		return "", xerrors.New("node name isn't defined, node-local mode requires kubernetes.nodeName")
+	}
+
+	return c.Config.Kubernetes.NodeName, nil
+}
+
+// contains returns true if the endpoint is located on the node and in the zone of
+// the locality. The zone of the node is used if the endpoint has no zone.
+func (l locality) contains(nodeName, zone *string) bool {
+	if l.node != "" && (nodeName == nil || *nodeName != l.node) {
+		return false
+	}
+
+	if l.zone == "" {
+		return true
+	}
+
+	switch {
+	case zone != nil:
+		return *zone == l.zone
+	case nodeName != nil:
+		return l.nodeZones[*nodeName] == l.zone
+	}
+
+	return false
+}
+
+// resolveZones reads the zone of nodes from their topology labels, zones are cached
+// for nodeZoneTTL. Nothing is read if the zone isn't set.
+func (c *Client) resolveZones(l *locality, nodeNames []string) error {
+	if l.zone == "" {
+		return nil
+	}
+
+	l.nodeZones = map[string]string{}
+
+	for _, name := range nodeNames {
+		if _, ok := l.nodeZones[name]; ok {
+			continue
+		}
+
+		if zone, ok := c.zones.get(name); ok {
+			l.nodeZones[name] = zone
+			continue
+		}
+
+		start := time.Now()
+		node, err := c.clientset.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+		c.Metrics.ObserveDiscovery(source, "get_node", start, ignoreNotFound(err))
+
+		switch {
+		case errors.IsNotFound(err):
+			// Endpoints of deleted nodes aren't in any zone.
+			l.nodeZones[name] = ""
+		case err != nil:
+			return xerrors.Errorf("unable to read the zone of the node, node: %s: %w", name, err)
+		default:
+			l.nodeZones[name] = nodeZone(node)
+		}
+
+		c.zones.set(name, l.nodeZones[name])
+	}
+
+	return nil
+}
+
+// zoneCache keeps the zone of nodes, so nodes aren't read on every request.
+type zoneCache struct {
+	mu    sync.Mutex
+	zones map[string]cachedZone
+}
+
+type cachedZone struct {
+	zone    string
+	expires time.Time
+}
+
+func (z *zoneCache) get(node string) (string, bool) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	cached, ok := z.zones[node]
+	if !ok || time.Now().After(cached.expires) {
+		return "", false
+	}
+
+	return cached.zone, true
+}
+
+func (z *zoneCache) set(node, zone string) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	if z.zones == nil {
+		z.zones = map[string]cachedZone{}
+	}
+
+	z.zones[node] = cachedZone{zone: zone, expires: time.Now().Add(nodeZoneTTL)}
+}
+
+// nodeZone returns the zone of the node from its topology labels.
+func nodeZone(node *v1.Node) string {
+	if zone, ok := node.Labels[v1.LabelTopologyZone]; ok {
+		return zone
+	}
+
+	return node.Labels[v1.LabelFailureDomainBetaZone]
+}
+
+// sliceNodes returns the nodes of endpoints without zone information.
+func sliceNodes(slices []discoveryv1.EndpointSlice) []string {
+	var nodes []string
+
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Zone == nil && endpoint.NodeName != nil {
+				nodes = append(nodes, *endpoint.NodeName)
+			}
+		}
+	}
+
+	return nodes
+}
+
+// addressNodes returns the nodes of all addresses.
+func addressNodes(endpoint *v1.Endpoints) []string {
+	var nodes []string
+
+	for _, subset := range endpoint.Subsets {
+		for _, addresses := range [][]v1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+			for _, address := range addresses {
+				if address.NodeName != nil {
+					nodes = append(nodes, *address.NodeName)
+				}
+			}
+		}
+	}
+
+	return nodes
+}
@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeLocal(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+	c.Kubernetes.NodeName = "node-a"
+
+	ready, nodeA, nodeB := true, "node-a", "node-b"
+	client := &Client{
+		Logger: logger,
+		Config: c,
+		clientset: fake.NewSimpleClientset(
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}},
+			&discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "api-abcde",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "api"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{Addresses: []string{"10.0.0.1"}, NodeName: &nodeB, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+				},
+			},
+		),
+	}
+
+	table := []struct {
+		query     string
+		nodeLocal bool
+		nodeName  string
+		healthy   bool
+		node      string
+		err       bool
+	}{
+		{query: "", healthy: true},
+		{query: "?local=true", nodeName: nodeA, healthy: false, node: nodeA},
+		{query: "", nodeLocal: true, nodeName: nodeB, healthy: true, node: nodeB},
+		{query: "?local=false", nodeLocal: true, nodeName: nodeA, healthy: true},
+		{query: "?local=true", err: true},
+		{query: "?local=maybe", nodeName: nodeA, err: true},
+	}
+
+	for _, item := range table {
+		c.Kubernetes.NodeLocal = item.nodeLocal
+		c.Kubernetes.NodeName = item.nodeName
+
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+		})
+		app.Get("/:namespace/:service", func(ctx *fiber.Ctx) error {
+			healthy, err := client.IsServiceHealthy(ctx)
+			if item.err {
+				assert.Error(t, err, item.query)
+				return nil
+			}
+
+			assert.NoError(t, err, item.query)
+			assert.Equal(t, item.healthy, healthy, item.query)
+
+			status, _ := endpoints.Get(ctx)
+			assert.Equal(t, item.node, status.Node, item.query)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost/default/api"+item.query, nil))
+		assert.NoError(t, err)
+	}
+}
+
+func TestZone(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	ready, nodeA, nodeB, zoneA := true, "node-a", "node-b", "zone-a"
+	client := &Client{
+		Logger: logger,
+		Config: c,
+		clientset: fake.NewSimpleClientset(
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}},
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeA, Labels: map[string]string{v1.LabelTopologyZone: zoneA}}},
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeB, Labels: map[string]string{v1.LabelFailureDomainBetaZone: "zone-b"}}},
+			&discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "api-abcde",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "api"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{Addresses: []string{"10.0.0.1"}, NodeName: &nodeA, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+					{Addresses: []string{"10.0.0.2"}, NodeName: &nodeB, Zone: &zoneA, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+				},
+			},
+			&v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+				Subsets: []v1.EndpointSubset{
+					{Addresses: []v1.EndpointAddress{{IP: "10.0.0.1", NodeName: &nodeA}, {IP: "10.0.0.2", NodeName: &nodeB}}},
+				},
+			},
+		),
+	}
+
+	table := []struct {
+		query          string
+		endpointSlices bool
+		expected       endpoints.Status
+	}{
+		{query: "?zone=zone-a", endpointSlices: true, expected: endpoints.Status{Ready: 2, Required: 1, Zone: zoneA}},
+		{query: "?zone=zone-b", endpointSlices: true, expected: endpoints.Status{Required: 1, Zone: "zone-b"}},
+		{query: "?zone=zone-a", endpointSlices: false, expected: endpoints.Status{Ready: 1, Required: 1, Zone: zoneA}},
+		{query: "?zone=zone-b", endpointSlices: false, expected: endpoints.Status{Ready: 1, Required: 1, Zone: "zone-b"}},
+	}
+
+	for _, item := range table {
+		c.Kubernetes.EndpointSlices = item.endpointSlices
+
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+		})
+		app.Get("/:namespace/:service", func(ctx *fiber.Ctx) error {
+			healthy, err := client.IsServiceHealthy(ctx)
+			assert.NoError(t, err, item.query)
+			assert.Equal(t, item.expected.Ready > 0, healthy, item.query)
+
+			status, _ := endpoints.Get(ctx)
+			assert.Equal(t, item.expected, status, item.query)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost/default/api"+item.query, nil))
+		assert.NoError(t, err)
+	}
+
+	// Zones of nodes are cached, each node is read once.
+	var gets int
+	for _, action := range client.clientset.(*fake.Clientset).Actions() {
+		if action.Matches("get", "nodes") {
+			gets++
+		}
+	}
+	assert.Equal(t, 2, gets)
+}
@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// portFilter restricts the endpoints that are counted to the ones exposing a port.
+type portFilter struct {
+	set    bool
+	name   string
+	number int32
+}
+
+// newPortFilter returns the filter of the port given by its name or number. A number
+// is resolved to the name of the service port if the service has such port, otherwise
+// it's the port of endpoints (the target port). The service is optional.
+func newPortFilter(value string, svc *v1.Service) portFilter {
+	if value == "" {
+		return portFilter{}
+	}
+
+	number, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return portFilter{set: true, name: value}
+	}
+
+	if svc != nil {
+		for _, port := range svc.Spec.Ports {
+			if port.Port == int32(number) {
+				return portFilter{set: true, name: port.Name}
+			}
+		}
+	}
+
+	return portFilter{set: true, number: int32(number)}
+}
+
+func (p portFilter) matches(name string, number int32) bool {
+	if p.number != 0 {
+		return p.number == number
+	}
+
+	return p.name == name
+}
+
+// slices returns the slices that expose the port.
+func (p portFilter) slices(slices []discoveryv1.EndpointSlice) []discoveryv1.EndpointSlice {
+	if !p.set {
+		return slices
+	}
+
+	var filtered []discoveryv1.EndpointSlice
+
+	for _, slice := range slices {
+		for _, port := range slice.Ports {
+			var (
+				name   string
+				number int32
+			)
+			if port.Name != nil {
+				name = *port.Name
+			}
+			if port.Port != nil {
+				number = *port.Port
+			}
+
+			if p.matches(name, number) {
+				filtered = append(filtered, slice)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// endpoints returns a copy of the endpoints with the subsets that expose the port.
+func (p portFilter) endpoints(endpoint *v1.Endpoints) *v1.Endpoints {
+	if !p.set {
+		return endpoint
+	}
+
+	filtered := &v1.Endpoints{ObjectMeta: endpoint.ObjectMeta}
+
+	for _, subset := range endpoint.Subsets {
+		for _, port := range subset.Ports {
+			if p.matches(port.Name, port.Port) {
+				filtered.Subsets = append(filtered.Subsets, subset)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// pod returns true if any container of the pod exposes the port.
+func (p portFilter) pod(pod *v1.Pod) bool {
+	if !p.set {
+		return true
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if p.matches(port.Name, port.ContainerPort) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestNewPortFilter(t *testing.T) {
+	t.Parallel()
+
+	svc := &v1.Service{
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)},
+				{Name: "metrics", Port: 9090, TargetPort: intstr.FromString("metrics")},
+			},
+		},
+	}
+
+	assert.Equal(t, portFilter{}, newPortFilter("", svc))
+	assert.Equal(t, portFilter{set: true, name: "metrics"}, newPortFilter("metrics", svc))
+	assert.Equal(t, portFilter{set: true, name: "http"}, newPortFilter("80", svc))
+	assert.Equal(t, portFilter{set: true, number: 8080}, newPortFilter("8080", svc))
+	assert.Equal(t, portFilter{set: true, number: 80}, newPortFilter("80", nil))
+}
+
+func TestPortFilter(t *testing.T) {
+	t.Parallel()
+
+	http, metrics := "http", "metrics"
+	port8080, port9090 := int32(8080), int32(9090)
+
+	slices := []discoveryv1.EndpointSlice{
+		{Ports: []discoveryv1.EndpointPort{{Name: &http, Port: &port8080}}},
+		{Ports: []discoveryv1.EndpointPort{{Name: &http, Port: &port8080}, {Name: &metrics, Port: &port9090}}},
+	}
+
+	assert.Equal(t, slices, portFilter{}.slices(slices))
+	assert.Equal(t, slices, portFilter{set: true, name: http}.slices(slices))
+	assert.Equal(t, slices[1:], portFilter{set: true, number: port9090}.slices(slices))
+	assert.Empty(t, portFilter{set: true, name: "grpc"}.slices(slices))
+
+	endpoint := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{
+			{Addresses: []v1.EndpointAddress{{IP: "10.0.0.1"}}, Ports: []v1.EndpointPort{{Name: http, Port: port8080}}},
+			{Addresses: []v1.EndpointAddress{{IP: "10.0.0.2"}}, Ports: []v1.EndpointPort{{Name: metrics, Port: port9090}}},
+		},
+	}
+
+	assert.Equal(t, endpoint, portFilter{}.endpoints(endpoint))
+	assert.Equal(t, endpoint.Subsets[1:], portFilter{set: true, name: metrics}.endpoints(endpoint).Subsets)
+
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Ports: []v1.ContainerPort{{Name: http, ContainerPort: port8080}}},
+			},
+		},
+	}
+
+	assert.True(t, portFilter{}.pod(pod))
+	assert.True(t, portFilter{set: true, number: port8080}.pod(pod))
+	assert.False(t, portFilter{set: true, name: metrics}.pod(pod))
+}
@@ -0,0 +1,179 @@
+package k8s
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/probe"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// probeNone disables the configured probe for a request.
+const probeNone = "none"
+
+// targetProbe checks addresses of services that have no readiness reported by Kubernetes,
+// i.e. ExternalName services and services without a selector.
+type targetProbe struct {
+	probe.Probe
+	// port overrides the port of the service or endpoints.
+	port int
+}
+
+// newProbe returns the probe of the request, the probe query parameter overrides the
+// configured type.
+func (c *Client) newProbe(ctx *fiber.Ctx) (targetProbe, error) {
+	kind := strings.ToLower(ctx.Query("probe", c.Config.Kubernetes.Probe))
+
+	if kind == probeNone {
+		kind = ""
+	}
+
+	if kind != "" && !probe.IsSupported(kind) {
+		return targetProbe{}, xerrors.Errorf("invalid probe, probe: %s", kind)
+	}
+
+	return targetProbe{
+		Probe: probe.Probe{
+			Type:               kind,
+			RequestPath:        ctx.Query("probePath", c.Config.Kubernetes.ProbePath),
+			Timeout:            c.Config.Kubernetes.ProbeTimeout,
+			InsecureSkipVerify: c.Config.Kubernetes.ProbeInsecureSkipVerify,
+		},
+		port: ctx.QueryInt("probePort", c.Config.Kubernetes.ProbePort),
+	}, nil
+}
+
+func (p targetProbe) enabled() bool {
+	return p.Type != ""
+}
+
+// externalNameStatus reports the ExternalName service as a single endpoint which is
+// ready if the external name resolves and, if enabled, the probe passes.
+func (c *Client) externalNameStatus(ctx *fiber.Ctx, svc *v1.Service, p targetProbe, pf portFilter) (endpoints.Status, error) {
+	requestID := ctx.GetRespHeader("X-Request-Id")
+	host := svc.Spec.ExternalName
+
+	lookupCtx, cancel := context.WithTimeout(context.Background(), c.Config.Kubernetes.ProbeTimeout)
+	defer cancel()
+
+	if _, err := net.DefaultResolver.LookupHost(lookupCtx, host); err != nil {
+		c.Logger.Debug("unable to resolve external name",
+			zap.String("request_id", requestID),
+			zap.String("external_name", host),
+			zap.Error(err),
+		)
+		return endpoints.Status{NotReady: 1}, nil
+	}
+
+	if !p.enabled() {
+		return endpoints.Status{Ready: 1}, nil
+	}
+
+	port := p.port
+	if port == 0 {
+		port = int(servicePort(svc, pf))
+	}
+	if port == 0 {
+		return endpoints.Status{}, xerrors.Errorf("probe port of ExternalName service isn't defined, service: %s", svc.Name)
+	}
+
+	if err := p.Check(net.JoinHostPort(host, strconv.Itoa(port))); err != nil {
+		c.Logger.Debug("external name probe failed",
+			zap.String("request_id", requestID),
+			zap.String("external_name", host),
+			zap.Error(err),
+		)
+		return endpoints.Status{NotReady: 1}, nil
+	}
+
+	return endpoints.Status{Ready: 1}, nil
+}
+
+// servicePort returns the service port matching the filter, or the first port of the
+// service if there is no filter.
+func servicePort(svc *v1.Service, pf portFilter) int32 {
+	for _, port := range svc.Spec.Ports {
+		if !pf.set || pf.matches(port.Name, port.Port) {
+			return port.Port
+		}
+	}
+
+	return pf.number
+}
+
+// probeAddresses probes ready addresses of manually managed endpoints, addresses that
+// don't pass the probe are counted as not ready.
+func (c *Client) probeAddresses(ctx *fiber.Ctx, endpoint *v1.Endpoints, l locality, p targetProbe, pf portFilter) (endpoints.Status, error) {
+	requestID := ctx.GetRespHeader("X-Request-Id")
+
+	var (
+		ready    int32
+		notReady int32
+	)
+
+	g := new(errgroup.Group)
+	g.SetLimit(c.Config.Concurrency)
+
+	for _, subset := range endpoint.Subsets {
+		port := p.port
+		if port == 0 {
+			port = int(subsetPort(subset, pf))
+		}
+		if port == 0 {
+			_ = g.Wait()
+			return endpoints.Status{}, xerrors.Errorf("probe port of endpoints isn't defined, endpoints: %s", endpoint.Name)
+		}
+
+		for _, address := range subset.Addresses {
+			if !l.contains(address.NodeName, nil) {
+				continue
+			}
+
+			addr := net.JoinHostPort(address.IP, strconv.Itoa(port))
+
+			g.Go(func() error {
+				if err := p.Check(addr); err != nil {
+					c.Logger.Debug("endpoint probe failed",
+						zap.String("request_id", requestID),
+						zap.String("addr", addr),
+						zap.Error(err),
+					)
+					atomic.AddInt32(&notReady, 1)
+					return nil
+				}
+				atomic.AddInt32(&ready, 1)
+				return nil
+			})
+		}
+
+		for _, address := range subset.NotReadyAddresses {
+			if l.contains(address.NodeName, nil) {
+				atomic.AddInt32(&notReady, 1)
+			}
+		}
+	}
+
+	_ = g.Wait()
+
+	return endpoints.Status{Ready: int(ready), NotReady: int(notReady)}, nil
+}
+
+// subsetPort returns the port of the subset matching the filter, or the first port of
+// the subset if there is no filter.
+func subsetPort(subset v1.EndpointSubset, pf portFilter) int32 {
+	for _, port := range subset.Ports {
+		if !pf.set || pf.matches(port.Name, port.Port) {
+			return port.Port
+		}
+	}
+
+	return 0
+}
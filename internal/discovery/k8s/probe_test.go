@@ -0,0 +1,145 @@
+package k8s
+
+import (
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newListener returns the port of a listener accepting connections, and the port of
+// a closed listener.
+func newListener(t *testing.T) (int32, int32) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	return int32(ln.Addr().(*net.TCPAddr).Port), int32(closed.Addr().(*net.TCPAddr).Port)
+}
+
+func TestProbe(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	open, closed := newListener(t)
+
+	client := &Client{
+		Logger: logger,
+		Config: c,
+		clientset: fake.NewSimpleClientset(
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "external"},
+				Spec: v1.ServiceSpec{
+					Type:         v1.ServiceTypeExternalName,
+					ExternalName: "localhost",
+					Ports: []v1.ServicePort{
+						{Name: "open", Port: open},
+						{Name: "closed", Port: closed},
+					},
+				},
+			},
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unresolvable"},
+				Spec: v1.ServiceSpec{
+					Type:         v1.ServiceTypeExternalName,
+					ExternalName: "unresolvable.invalid",
+				},
+			},
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "manual"}},
+			&v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "manual"},
+				Subsets: []v1.EndpointSubset{
+					{
+						Addresses:         []v1.EndpointAddress{{IP: "127.0.0.1"}},
+						NotReadyAddresses: []v1.EndpointAddress{{IP: "127.0.0.2"}},
+						Ports:             []v1.EndpointPort{{Name: "open", Port: open}},
+					},
+					{
+						Addresses: []v1.EndpointAddress{{IP: "127.0.0.1"}},
+						Ports:     []v1.EndpointPort{{Name: "closed", Port: closed}},
+					},
+				},
+			},
+		),
+	}
+
+	table := []struct {
+		path     string
+		probe    string
+		healthy  bool
+		expected endpoints.Status
+		err      bool
+	}{
+		{path: "/default/external", healthy: true, expected: endpoints.Status{Ready: 1, Required: 1}},
+		{path: "/default/external", probe: "tcp", healthy: true, expected: endpoints.Status{Ready: 1, Required: 1}},
+		{path: "/default/external?port=closed", probe: "tcp", healthy: false, expected: endpoints.Status{NotReady: 1, Required: 1}},
+		{path: "/default/external?probePort=" + strconv.Itoa(int(closed)), probe: "tcp", healthy: false, expected: endpoints.Status{NotReady: 1, Required: 1}},
+		{path: "/default/external?port=closed&probe=none", probe: "tcp", healthy: true, expected: endpoints.Status{Ready: 1, Required: 1}},
+		{path: "/default/unresolvable", healthy: false, expected: endpoints.Status{NotReady: 1, Required: 1}},
+		{path: "/default/unresolvable?probe=tcp", healthy: false, expected: endpoints.Status{NotReady: 1, Required: 1}},
+		// Without the probe, addresses of manually managed endpoints are counted as reported.
+		{path: "/default/manual", healthy: true, expected: endpoints.Status{Ready: 2, NotReady: 1, Required: 1}},
+		{path: "/default/manual", probe: "tcp", healthy: true, expected: endpoints.Status{Ready: 1, NotReady: 2, Required: 1}},
+		{path: "/default/manual?minReady=2", probe: "tcp", healthy: false, expected: endpoints.Status{Ready: 1, NotReady: 2, Required: 2}},
+		{path: "/default/manual?port=closed", probe: "tcp", healthy: false, expected: endpoints.Status{NotReady: 1, Required: 1}},
+		{path: "/default/manual?probe=grpc", err: true},
+	}
+
+	for _, item := range table {
+		c.Kubernetes.Probe = item.probe
+		c.Kubernetes.EndpointSlices = false
+
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+		})
+		app.Get("/:namespace/:service", func(ctx *fiber.Ctx) error {
+			healthy, err := client.IsServiceHealthy(ctx)
+			if item.err {
+				assert.Error(t, err, item.path)
+				return nil
+			}
+
+			assert.NoError(t, err, item.path)
+			assert.Equal(t, item.healthy, healthy, item.path)
+
+			status, _ := endpoints.Get(ctx)
+			assert.Equal(t, item.expected, status, item.path)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost"+item.path, nil))
+		assert.NoError(t, err)
+	}
+}
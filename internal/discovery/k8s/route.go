@@ -0,0 +1,349 @@
+package k8s
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	KindIngress   = "ingress"
+	KindHTTPRoute = "httproute"
+)
+
+const (
+	// PolicyAll requires all backends of a route to be healthy.
+	PolicyAll = "all"
+	// PolicyAny requires at least one healthy backend.
+	PolicyAny = "any"
+	// PolicyQuorum requires more than half of backends to be healthy.
+	PolicyQuorum = "quorum"
+)
+
+// routeKey is the key of backends of the route in the request context.
+const routeKey = "healthgroup.kubernetes.route"
+
+// httpRouteResource is the Gateway API resource of HTTPRoutes, it's read by the dynamic
+// client as client-go has no typed client of the Gateway API.
+var httpRouteResource = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "httproutes",
+}
+
+// Route evaluates the backend services of an Ingress or an HTTPRoute. Each backend is
+// evaluated in the same way as a service, routes are always read from the API server.
+type Route struct {
+	*Client
+	kind string
+}
+
+// backend is a service referenced by a route. The port is either a name or a number of
+// the service port, all ports if empty.
+type backend struct {
+	namespace string
+	service   string
+	port      string
+}
+
+// NewRoute returns the adapter of routes of the given kind.
+func NewRoute(clients *Clients, kind string) (*Route, error) {
+	switch kind {
+	case KindIngress, KindHTTPRoute:
+	default:
+		return nil, xerrors.Errorf("route kind is not supported, kind: %s", kind)
+	}
+
+	client, err := clients.Get("")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Route{Client: client, kind: kind}, nil
+}
+
+// IsServiceExists returns false if the route doesn't exist.
+func (r *Route) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
+	_, err := r.backends(ctx)
+
+	if errors.IsNotFound(err) {
+		r.Logger.Debug("Kubernetes route doesn't exist",
+			zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+			zap.String("namespace", ctx.Params("namespace")),
+			zap.String("kind", r.kind),
+			zap.String("name", ctx.Params("name")),
+		)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsServiceHealthy returns true if enough backends of the route are healthy, as required
+// by the backend policy. Healthy backends are counted as ready endpoints of the route.
+func (r *Route) IsServiceHealthy(ctx *fiber.Ctx) (bool, error) {
+	backends, err := r.backends(ctx)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	policy := strings.ToLower(ctx.Query("policy", r.Config.Kubernetes.BackendPolicy))
+
+	var status endpoints.Status
+
+	status.Required, err = requiredBackends(policy, len(backends))
+	if err != nil {
+		return false, err
+	}
+
+	l, err := r.newLocality(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	p, err := r.newProbe(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	status.Node = l.node
+	status.Zone = l.zone
+
+	for _, b := range backends {
+		result, err := r.evaluateBackend(ctx, b, l, p)
+		if err != nil {
+			return false, err
+		}
+
+		if result.Healthy {
+			status.Ready++
+		} else {
+			status.NotReady++
+		}
+		status.Backends = append(status.Backends, result)
+	}
+	endpoints.Set(ctx, status)
+
+	healthy := status.Ready >= status.Required
+
+	r.Logger.Debug("Kubernetes route backends",
+		zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+		zap.String("namespace", ctx.Params("namespace")),
+		zap.String("kind", r.kind),
+		zap.String("name", ctx.Params("name")),
+		zap.String("policy", policy),
+		zap.Int("healthy", status.Ready),
+		zap.Int("unhealthy", status.NotReady),
+		zap.Int("required", status.Required),
+		zap.Bool("healthy", healthy),
+	)
+
+	return healthy, nil
+}
+
+// evaluateBackend evaluates endpoints of the backend service, the minimum of ready
+// endpoints applies to each backend.
+func (r *Route) evaluateBackend(ctx *fiber.Ctx, b backend, l locality, p targetProbe) (endpoints.Backend, error) {
+	result := endpoints.Backend{
+		Namespace: b.namespace,
+		Service:   b.service,
+		Port:      b.port,
+	}
+
+	status, exists, err := r.serviceStatus(ctx, b.namespace, b.service, b.port, l, p)
+	if err != nil {
+		return result, xerrors.Errorf("unable to evaluate backend, service: %s/%s: %w", b.namespace, b.service, err)
+	}
+
+	result.Exists = exists
+	if !exists {
+		return result, nil
+	}
+
+	status.Required, err = minReady(ctx.Query("minReady", r.Config.Kubernetes.MinReady), status.Ready+status.NotReady)
+	if err != nil {
+		return result, err
+	}
+
+	result.Status = status
+	result.Healthy = status.Ready >= status.Required
+
+	return result, nil
+}
+
+// requiredBackends returns the number of healthy backends out of total required by the
+// policy. A route without backends is never healthy.
+func requiredBackends(policy string, total int) (int, error) {
+	var required int
+
+	switch policy {
+	case PolicyAll:
+		required = total
+	case PolicyAny:
+		required = 1
+	case PolicyQuorum:
+		required = total/2 + 1 //nolint:gomnd
+	default:
+		return 0, xerrors.Errorf("invalid backend policy, policy: %s", policy)
+	}
+
+	if required < 1 {
+		return 1, nil
+	}
+
+	return required, nil
+}
+
+// backends returns the backends of the route of the request. The backends are kept in
+// the request context, so the route is read only once per request.
+func (r *Route) backends(ctx *fiber.Ctx) ([]backend, error) {
+	if backends, ok := ctx.Locals(routeKey).([]backend); ok {
+		return backends, nil
+	}
+
+	namespace := ctx.Params("namespace")
+	name := ctx.Params("name")
+
+	var (
+		backends []backend
+		err      error
+	)
+
+	start := time.Now()
+
+	switch r.kind {
+	case KindIngress:
+		var ingress *networkingv1.Ingress
+		ingress, err = r.clientset.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err == nil {
+			backends = ingressBackends(ingress)
+		}
+	case KindHTTPRoute:
+		var route *unstructured.Unstructured
+		route, err = r.dynamic.Resource(httpRouteResource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err == nil {
+			backends, err = httpRouteBackends(route)
+		}
+	}
+
+	r.Metrics.ObserveDiscovery(source, "get_"+r.kind, start, ignoreNotFound(err))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Locals(routeKey, backends)
+
+	return backends, nil
+}
+
+// ingressBackends returns the services of the default backend and of all paths of the
+// ingress. Resource backends aren't services, they are ignored.
+func ingressBackends(ingress *networkingv1.Ingress) []backend {
+	var (
+		backends []backend
+		seen     = map[backend]bool{}
+	)
+
+	add := func(service *networkingv1.IngressServiceBackend) {
+		if service == nil {
+			return
+		}
+
+		b := backend{namespace: ingress.Namespace, service: service.Name, port: service.Port.Name}
+		if service.Port.Number != 0 {
+			b.port = strconv.Itoa(int(service.Port.Number))
+		}
+
+		if !seen[b] {
+			seen[b] = true
+			backends = append(backends, b)
+		}
+	}
+
+	if ingress.Spec.DefaultBackend != nil {
+		add(ingress.Spec.DefaultBackend.Service)
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			add(path.Backend.Service)
+		}
+	}
+
+	return backends
+}
+
+// httpRouteBackends returns the services referenced by backendRefs of all rules of the
+// HTTPRoute. References to other kinds than Service are ignored.
+func httpRouteBackends(route *unstructured.Unstructured) ([]backend, error) {
+	rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if err != nil {
+		return nil, xerrors.Errorf("invalid HTTPRoute rules, name: %s: %w", route.GetName(), err)
+	}
+
+	var (
+		backends []backend
+		seen     = map[backend]bool{}
+	)
+
+	for _, rule := range rules {
+		rule, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		refs, _, err := unstructured.NestedSlice(rule, "backendRefs")
+		if err != nil {
+			return nil, xerrors.Errorf("invalid HTTPRoute backendRefs, name: %s: %w", route.GetName(), err)
+		}
+
+		for _, ref := range refs {
+			ref, ok := ref.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			group, _, _ := unstructured.NestedString(ref, "group")
+			kind, _, _ := unstructured.NestedString(ref, "kind")
+			if group != "" || (kind != "" && kind != "Service") {
+				continue
+			}
+
+			b := backend{namespace: route.GetNamespace()}
+			b.service, _, _ = unstructured.NestedString(ref, "name")
+			if namespace, _, _ := unstructured.NestedString(ref, "namespace"); namespace != "" {
+				b.namespace = namespace
+			}
+			if port, ok, _ := unstructured.NestedInt64(ref, "port"); ok {
+				b.port = strconv.FormatInt(port, 10)
+			}
+
+			if !seen[b] {
+				seen[b] = true
+				backends = append(backends, b)
+			}
+		}
+	}
+
+	return backends, nil
+}
@@ -0,0 +1,302 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRequiredBackends(t *testing.T) {
+	table := []struct {
+		policy   string
+		total    int
+		expected int
+		err      bool
+	}{
+		{policy: PolicyAll, total: 3, expected: 3},
+		{policy: PolicyAll, total: 0, expected: 1},
+		{policy: PolicyAny, total: 3, expected: 1},
+		{policy: PolicyQuorum, total: 3, expected: 2},
+		{policy: PolicyQuorum, total: 4, expected: 3},
+		{policy: PolicyQuorum, total: 1, expected: 1},
+		{policy: "most", total: 3, err: true},
+	}
+
+	for _, item := range table {
+		required, err := requiredBackends(item.policy, item.total)
+		if item.err {
+			assert.Error(t, err, item.policy)
+			continue
+		}
+		assert.NoError(t, err, item.policy)
+		assert.Equal(t, item.expected, required, item.policy)
+	}
+}
+
+func newIngress() *networkingv1.Ingress {
+	prefix := networkingv1.PathTypePrefix
+	path := func(service string, port networkingv1.ServiceBackendPort) networkingv1.HTTPIngressPath {
+		return networkingv1.HTTPIngressPath{
+			Path:     "/" + service,
+			PathType: &prefix,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: service, Port: port},
+			},
+		}
+	}
+
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shop"},
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								path("api", networkingv1.ServiceBackendPort{Name: "http"}),
+								path("web", networkingv1.ServiceBackendPort{Number: 80}),
+								path("missing", networkingv1.ServiceBackendPort{Name: "http"}),
+								{
+									Path: "/static",
+									Backend: networkingv1.IngressBackend{
+										Resource: &v1.TypedLocalObjectReference{Kind: "Bucket", Name: "static"},
+									},
+								},
+							},
+						},
+					},
+				},
+				{Host: "empty.example.com"},
+			},
+		},
+	}
+}
+
+func newHTTPRoute() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata": map[string]interface{}{
+			"namespace": "default",
+			"name":      "store",
+		},
+		"spec": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"backendRefs": []interface{}{
+						map[string]interface{}{"name": "web", "port": int64(80), "weight": int64(90)},
+						map[string]interface{}{"name": "auth", "namespace": "other", "port": int64(80)},
+					},
+				},
+				map[string]interface{}{
+					"backendRefs": []interface{}{
+						map[string]interface{}{"kind": "Service", "name": "web", "port": int64(80)},
+						map[string]interface{}{"group": "example.com", "kind": "Bucket", "name": "static"},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestIngressBackends(t *testing.T) {
+	assert.Equal(t, []backend{
+		{namespace: "default", service: "web", port: "80"},
+		{namespace: "default", service: "api", port: "http"},
+		{namespace: "default", service: "missing", port: "http"},
+	}, ingressBackends(newIngress()))
+}
+
+func TestHTTPRouteBackends(t *testing.T) {
+	backends, err := httpRouteBackends(newHTTPRoute())
+	assert.NoError(t, err)
+	assert.Equal(t, []backend{
+		{namespace: "default", service: "web", port: "80"},
+		{namespace: "other", service: "auth", port: "80"},
+	}, backends)
+}
+
+func TestRoute(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	ready, notReady, http := true, false, "http"
+	service := func(namespace, name string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: v1.ServiceSpec{
+				Selector: map[string]string{"app": name},
+				Ports:    []v1.ServicePort{{Name: http, Port: 80}},
+			},
+		}
+	}
+	slice := func(namespace, name string, conditions ...bool) *discoveryv1.EndpointSlice {
+		s := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name + "-abcde",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: name},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       []discoveryv1.EndpointPort{{Name: &http}},
+		}
+		for i := range conditions {
+			s.Endpoints = append(s.Endpoints, discoveryv1.Endpoint{
+				Addresses:  []string{fmt.Sprintf("10.0.0.%d", i+1)},
+				Conditions: discoveryv1.EndpointConditions{Ready: &conditions[i]},
+			})
+		}
+		return s
+	}
+
+	client := &Client{
+		Logger: logger,
+		Config: c,
+		clientset: fake.NewSimpleClientset(
+			service("default", "web"), slice("default", "web", ready, ready),
+			service("default", "api"), slice("default", "api", notReady),
+			service("other", "auth"), slice("other", "auth", ready),
+			newIngress(),
+		),
+		dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{httpRouteResource: "HTTPRouteList"},
+			newHTTPRoute(),
+		),
+	}
+
+	table := []struct {
+		kind     string
+		path     string
+		exists   bool
+		healthy  bool
+		expected endpoints.Status
+		backends []bool
+		err      bool
+	}{
+		{
+			kind:     KindIngress,
+			path:     "/default/shop",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 1, NotReady: 2, Required: 3},
+			backends: []bool{true, false, false},
+		},
+		{
+			kind:     KindIngress,
+			path:     "/default/shop?policy=any",
+			exists:   true,
+			healthy:  true,
+			expected: endpoints.Status{Ready: 1, NotReady: 2, Required: 1},
+			backends: []bool{true, false, false},
+		},
+		{
+			kind:     KindIngress,
+			path:     "/default/shop?policy=quorum",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 1, NotReady: 2, Required: 2},
+			backends: []bool{true, false, false},
+		},
+		{
+			kind:     KindHTTPRoute,
+			path:     "/default/store?minReady=2",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 1, NotReady: 1, Required: 2},
+			backends: []bool{true, false},
+		},
+		{
+			kind:     KindHTTPRoute,
+			path:     "/default/store",
+			exists:   true,
+			healthy:  true,
+			expected: endpoints.Status{Ready: 2, Required: 2},
+			backends: []bool{true, true},
+		},
+		{
+			kind:   KindHTTPRoute,
+			path:   "/default/shop",
+			exists: false,
+		},
+		{
+			kind:   KindIngress,
+			path:   "/default/shop?policy=most",
+			exists: true,
+			err:    true,
+		},
+	}
+
+	for _, item := range table {
+		r := &Route{Client: client, kind: item.kind}
+
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+		})
+		app.Get("/:namespace/:name", func(ctx *fiber.Ctx) error {
+			exists, err := r.IsServiceExists(ctx)
+			assert.NoError(t, err, item.path)
+			assert.Equal(t, item.exists, exists, item.path)
+			if !exists {
+				return nil
+			}
+
+			healthy, err := r.IsServiceHealthy(ctx)
+			if item.err {
+				assert.Error(t, err, item.path)
+				return nil
+			}
+			assert.NoError(t, err, item.path)
+			assert.Equal(t, item.healthy, healthy, item.path)
+
+			status, _ := endpoints.Get(ctx)
+			backends := status.Backends
+			status.Backends = nil
+			assert.Equal(t, item.expected, status, item.path)
+
+			var results []bool
+			for _, b := range backends {
+				results = append(results, b.Healthy)
+			}
+			assert.Equal(t, item.backends, results, item.path)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost"+item.path, nil))
+		assert.NoError(t, err)
+	}
+}
+
+func TestNewRoute(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{}
+	clients := &Clients{clients: map[string]*Client{"": client}}
+
+	r, err := NewRoute(clients, KindIngress)
+	assert.NoError(t, err)
+	assert.Same(t, client, r.Client)
+
+	_, err = NewRoute(clients, "gateway")
+	assert.Error(t, err)
+}
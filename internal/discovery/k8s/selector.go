@@ -0,0 +1,170 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// podsKey is the key of the selected pods in the request context.
+const podsKey = "healthgroup.kubernetes.pods"
+
+// Selector evaluates pods selected by a label selector, without a Service object.
+// Pods are always read from the API server, they aren't watched by informers.
+type Selector struct {
+	*Client
+}
+
+// NewSelector returns the label selector adapter, it shares the client of the cluster
+// given by flags.
+func NewSelector(clients *Clients) (*Selector, error) {
+	client, err := clients.Get("")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Selector{Client: client}, nil
+}
+
+// IsServiceExists returns false if no pod matches the selector.
+func (s *Selector) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
+	pods, err := s.pods(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if len(pods) == 0 {
+		s.Logger.Debug("Kubernetes pods don't exist",
+			zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+			zap.String("namespace", ctx.Params("namespace")),
+			zap.String("selector", ctx.Query("selector")),
+		)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// IsServiceHealthy returns true if enough selected pods are ready.
+func (s *Selector) IsServiceHealthy(ctx *fiber.Ctx) (bool, error) {
+	pods, err := s.pods(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	l, err := s.newLocality(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	p := newPortFilter(ctx.Query("port"), nil)
+
+	var (
+		filtered []v1.Pod
+		nodes    []string
+	)
+	for i := range pods {
+		if !p.pod(&pods[i]) {
+			continue
+		}
+
+		filtered = append(filtered, pods[i])
+		if pods[i].Spec.NodeName != "" {
+			nodes = append(nodes, pods[i].Spec.NodeName)
+		}
+	}
+
+	if err := s.resolveZones(&l, nodes); err != nil {
+		return false, err
+	}
+
+	return s.evaluate(ctx, ctx.Query("selector"), l, countPods(filtered, s.Config.Kubernetes.Condition, l))
+}
+
+// pods returns the pods matching the selector of the request. The pods are kept in
+// the request context, so they're listed only once per request.
+func (s *Selector) pods(ctx *fiber.Ctx) ([]v1.Pod, error) {
+	if pods, ok := ctx.Locals(podsKey).([]v1.Pod); ok {
+		return pods, nil
+	}
+
+	selector := ctx.Query("selector")
+	if selector == "" {
+		return nil, xerrors.New("label selector isn't defined, selector is required")
+	}
+
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, xerrors.Errorf("invalid label selector, selector: %s: %w", selector, err)
+	}
+
+	start := time.Now()
+	list, err := s.clientset.CoreV1().Pods(ctx.Params("namespace")).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	s.Metrics.ObserveDiscovery(source, "list_pods", start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Completed pods aren't endpoints.
+	pods := make([]v1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+			pods = append(pods, pod)
+		}
+	}
+
+	ctx.Locals(podsKey, pods)
+
+	return pods, nil
+}
+
+// countPods returns the number of ready, not ready and terminating pods within the
+// locality. Terminating pods are ready only if the condition is serving, like
+// endpoints of EndpointSlices.
+func countPods(pods []v1.Pod, condition string, l locality) endpoints.Status {
+	var status endpoints.Status
+
+	for i := range pods {
+		pod := &pods[i]
+
+		nodeName := pod.Spec.NodeName
+		if !l.contains(&nodeName, nil) {
+			continue
+		}
+
+		terminating := pod.DeletionTimestamp != nil
+		if terminating {
+			status.Terminating++
+		}
+
+		ready := podReady(pod) && (!terminating || condition == ConditionServing)
+
+		switch {
+		case ready:
+			status.Ready++
+		case !terminating:
+			status.NotReady++
+		}
+	}
+
+	return status
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package k8s
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCountPods(t *testing.T) {
+	t.Parallel()
+
+	now := metav1.Now()
+	ready := []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	notReady := []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}}
+
+	pods := []v1.Pod{
+		{Spec: v1.PodSpec{NodeName: "node-a"}, Status: v1.PodStatus{Conditions: ready}},
+		{Spec: v1.PodSpec{NodeName: "node-b"}, Status: v1.PodStatus{Conditions: ready}},
+		{Spec: v1.PodSpec{NodeName: "node-a"}, Status: v1.PodStatus{Conditions: notReady}},
+		{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}, Spec: v1.PodSpec{NodeName: "node-a"}, Status: v1.PodStatus{Conditions: ready}},
+		{},
+	}
+
+	assert.Equal(t, endpoints.Status{Ready: 2, NotReady: 2, Terminating: 1}, countPods(pods, ConditionReady, locality{}))
+	assert.Equal(t, endpoints.Status{Ready: 3, NotReady: 2, Terminating: 1}, countPods(pods, ConditionServing, locality{}))
+	assert.Equal(t, endpoints.Status{Ready: 1, NotReady: 1, Terminating: 1}, countPods(pods, ConditionReady, locality{node: "node-a"}))
+}
+
+func TestSelector(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	pod := func(name, track string, isReady bool, port int32) *v1.Pod {
+		status := v1.ConditionFalse
+		if isReady {
+			status = v1.ConditionTrue
+		}
+
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels:    map[string]string{"app": "api", "track": track},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Ports: []v1.ContainerPort{{ContainerPort: port}}}},
+			},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+			},
+		}
+	}
+
+	selector := &Selector{
+		Client: &Client{
+			Logger: logger,
+			Config: c,
+			clientset: fake.NewSimpleClientset(
+				pod("api-1", "stable", true, 8080),
+				pod("api-2", "stable", false, 8080),
+				pod("api-3", "canary", false, 9090),
+			),
+		},
+	}
+
+	table := []struct {
+		query    string
+		exists   bool
+		healthy  bool
+		expected endpoints.Status
+		err      bool
+	}{
+		{query: "?selector=app=api", exists: true, healthy: true, expected: endpoints.Status{Ready: 1, NotReady: 2, Required: 1}},
+		{query: "?selector=app=api,track=canary", exists: true, healthy: false, expected: endpoints.Status{NotReady: 1, Required: 1}},
+		{query: "?selector=app=api&port=9090", exists: true, healthy: false, expected: endpoints.Status{NotReady: 1, Required: 1}},
+		{query: "?selector=app=worker", exists: false},
+		{query: "?selector=app=(", err: true},
+		{query: "", err: true},
+	}
+
+	for _, item := range table {
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+		})
+		app.Get("/:namespace", func(ctx *fiber.Ctx) error {
+			exists, err := selector.IsServiceExists(ctx)
+			if item.err {
+				assert.Error(t, err, item.query)
+				return nil
+			}
+
+			assert.NoError(t, err, item.query)
+			assert.Equal(t, item.exists, exists, item.query)
+			if !exists {
+				return nil
+			}
+
+			healthy, err := selector.IsServiceHealthy(ctx)
+			assert.NoError(t, err, item.query)
+			assert.Equal(t, item.healthy, healthy, item.query)
+
+			status, _ := endpoints.Get(ctx)
+			assert.Equal(t, item.expected, status, item.query)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost/default"+item.query, nil))
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,241 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/quorum"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindDeployment  = "deployment"
+	KindStatefulSet = "statefulset"
+	KindDaemonSet   = "daemonset"
+)
+
+// workloadKey is the key of the workload status in the request context.
+const workloadKey = "healthgroup.kubernetes.workload"
+
+// Workload evaluates the rollout state of a workload instead of endpoints of a service.
+// Workloads are always read from the API server, they aren't watched by informers.
+type Workload struct {
+	*Client
+	kind string
+}
+
+// workloadStatus is the replica counts and conditions common to all kinds of workloads.
+type workloadStatus struct {
+	desired   int
+	available int
+	updated   int
+	// reason is set if a condition of the workload makes it unhealthy.
+	reason string
+}
+
+// NewWorkload returns the adapter of workloads of the given kind.
+func NewWorkload(clients *Clients, kind string) (*Workload, error) {
+	switch kind {
+	case KindDeployment, KindStatefulSet, KindDaemonSet:
+	default:
+		return nil, xerrors.Errorf("workload kind is not supported, kind: %s", kind)
+	}
+
+	client, err := clients.Get("")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Workload{Client: client, kind: kind}, nil
+}
+
+// IsServiceExists returns false if the workload doesn't exist.
+func (w *Workload) IsServiceExists(ctx *fiber.Ctx) (bool, error) {
+	_, err := w.status(ctx)
+
+	if errors.IsNotFound(err) {
+		w.Logger.Debug("Kubernetes workload doesn't exist",
+			zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+			zap.String("namespace", ctx.Params("namespace")),
+			zap.String("kind", w.kind),
+			zap.String("name", ctx.Params("name")),
+		)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// IsServiceHealthy returns true if enough replicas are available and updated, and
+// no condition of the workload reports a failure.
+func (w *Workload) IsServiceHealthy(ctx *fiber.Ctx) (bool, error) {
+	ws, err := w.status(ctx)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	status := endpoints.Status{
+		Ready:   ws.available,
+		Updated: ws.updated,
+	}
+	if ws.desired > ws.available {
+		status.NotReady = ws.desired - ws.available
+	}
+
+	status.Required, err = minReady(ctx.Query("minReady", w.Config.Kubernetes.MinReady), ws.desired)
+	if err != nil {
+		return false, err
+	}
+
+	status.RequiredUpdated, err = minUpdated(ctx.Query("minUpdated", w.Config.Kubernetes.MinUpdated), ws.desired)
+	if err != nil {
+		return false, err
+	}
+	endpoints.Set(ctx, status)
+
+	healthy := ws.reason == "" && status.Ready >= status.Required && status.Updated >= status.RequiredUpdated
+
+	w.Logger.Debug("Kubernetes workload replicas",
+		zap.String("request_id", ctx.GetRespHeader("X-Request-Id")),
+		zap.String("namespace", ctx.Params("namespace")),
+		zap.String("kind", w.kind),
+		zap.String("name", ctx.Params("name")),
+		zap.Int("desired", ws.desired),
+		zap.Int("available", ws.available),
+		zap.Int("updated", ws.updated),
+		zap.Int("required", status.Required),
+		zap.Int("required_updated", status.RequiredUpdated),
+		zap.String("reason", ws.reason),
+		zap.Bool("healthy", healthy),
+	)
+
+	return healthy, nil
+}
+
+// status returns the status of the workload of the request. The status is kept in
+// the request context, so the workload is read only once per request.
+func (w *Workload) status(ctx *fiber.Ctx) (workloadStatus, error) {
+	if ws, ok := ctx.Locals(workloadKey).(workloadStatus); ok {
+		return ws, nil
+	}
+
+	namespace := ctx.Params("namespace")
+	name := ctx.Params("name")
+
+	var (
+		ws  workloadStatus
+		err error
+	)
+
+	start := time.Now()
+
+	switch w.kind {
+	case KindDeployment:
+		var deployment *appsv1.Deployment
+		deployment, err = w.clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err == nil {
+			ws = deploymentStatus(deployment)
+		}
+	case KindStatefulSet:
+		var statefulSet *appsv1.StatefulSet
+		statefulSet, err = w.clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err == nil {
+			ws = statefulSetStatus(statefulSet)
+		}
+	case KindDaemonSet:
+		var daemonSet *appsv1.DaemonSet
+		daemonSet, err = w.clientset.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err == nil {
+			ws = daemonSetStatus(daemonSet)
+		}
+	}
+
+	w.Metrics.ObserveDiscovery(source, "get_"+w.kind, start, ignoreNotFound(err))
+
+	if err != nil {
+		return ws, err
+	}
+
+	ctx.Locals(workloadKey, ws)
+
+	return ws, nil
+}
+
+// deploymentStatus returns the status of the deployment. The deployment is unhealthy
+// if it isn't available or its rollout doesn't progress.
+func deploymentStatus(deployment *appsv1.Deployment) workloadStatus {
+	ws := workloadStatus{
+		desired:   int(replicas(deployment.Spec.Replicas)),
+		available: int(deployment.Status.AvailableReplicas),
+		updated:   int(deployment.Status.UpdatedReplicas),
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Status != v1.ConditionFalse {
+			continue
+		}
+
+		switch condition.Type {
+		case appsv1.DeploymentAvailable, appsv1.DeploymentProgressing:
+			ws.reason = string(condition.Type)
+			if condition.Reason != "" {
+				ws.reason += ": " + condition.Reason
+			}
+			return ws
+		}
+	}
+
+	return ws
+}
+
+func statefulSetStatus(statefulSet *appsv1.StatefulSet) workloadStatus {
+	return workloadStatus{
+		desired:   int(replicas(statefulSet.Spec.Replicas)),
+		available: int(statefulSet.Status.AvailableReplicas),
+		updated:   int(statefulSet.Status.UpdatedReplicas),
+	}
+}
+
+func daemonSetStatus(daemonSet *appsv1.DaemonSet) workloadStatus {
+	return workloadStatus{
+		desired:   int(daemonSet.Status.DesiredNumberScheduled),
+		available: int(daemonSet.Status.NumberAvailable),
+		updated:   int(daemonSet.Status.UpdatedNumberScheduled),
+	}
+}
+
+// replicas returns the desired number of replicas, one if it isn't defined.
+func replicas(value *int32) int32 {
+	if value == nil {
+		return 1
+	}
+
+	return *value
+}
+
+// minUpdated returns the minimum number of updated replicas out of desired. The value
+// is either a number or a percentage of desired replicas, none are required by default.
+func minUpdated(value string, desired int) (int, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+
+	required, err := quorum.Required(value, desired)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid minimum of updated replicas, minUpdated: %s: %w", value, err)
+	}
+
+	return required, nil
+}
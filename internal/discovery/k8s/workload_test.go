@@ -0,0 +1,174 @@
+package k8s
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMinUpdated(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		value    string
+		desired  int
+		expected int
+		err      bool
+	}{
+		{value: "", desired: 4, expected: 0},
+		{value: "2", desired: 4, expected: 2},
+		{value: "100%", desired: 4, expected: 4},
+		{value: "50%", desired: 3, expected: 2},
+		{value: "all", desired: 3, err: true},
+	}
+
+	for _, item := range table {
+		required, err := minUpdated(item.value, item.desired)
+		if item.err {
+			assert.Error(t, err, item.value)
+			continue
+		}
+
+		assert.NoError(t, err, item.value)
+		assert.Equal(t, item.expected, required, item.value)
+	}
+}
+
+func TestWorkload(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	three := int32(3)
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "default", Name: name}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: meta("api"),
+			Spec:       appsv1.DeploymentSpec{Replicas: &three},
+			Status: appsv1.DeploymentStatus{
+				AvailableReplicas: 3,
+				UpdatedReplicas:   1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: v1.ConditionTrue},
+					{Type: appsv1.DeploymentProgressing, Status: v1.ConditionTrue},
+				},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: meta("stuck"),
+			Spec:       appsv1.DeploymentSpec{Replicas: &three},
+			Status: appsv1.DeploymentStatus{
+				AvailableReplicas: 3,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Status: v1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+				},
+			},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: meta("db"),
+			Spec:       appsv1.StatefulSetSpec{Replicas: &three},
+			Status:     appsv1.StatefulSetStatus{AvailableReplicas: 1, UpdatedReplicas: 3},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: meta("agent"),
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 4, NumberAvailable: 4, UpdatedNumberScheduled: 4},
+		},
+	)
+
+	table := []struct {
+		kind     string
+		path     string
+		exists   bool
+		healthy  bool
+		expected endpoints.Status
+	}{
+		{
+			kind:     KindDeployment,
+			path:     "/default/api",
+			exists:   true,
+			healthy:  true,
+			expected: endpoints.Status{Ready: 3, Required: 1, Updated: 1},
+		},
+		{
+			kind:     KindDeployment,
+			path:     "/default/api?minUpdated=100%25",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 3, Required: 1, Updated: 1, RequiredUpdated: 3},
+		},
+		{
+			kind:     KindDeployment,
+			path:     "/default/stuck",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 3, Required: 1},
+		},
+		{
+			kind:     KindStatefulSet,
+			path:     "/default/db?minReady=2",
+			exists:   true,
+			healthy:  false,
+			expected: endpoints.Status{Ready: 1, NotReady: 2, Required: 2, Updated: 3},
+		},
+		{
+			kind:     KindDaemonSet,
+			path:     "/default/agent?minReady=100%25",
+			exists:   true,
+			healthy:  true,
+			expected: endpoints.Status{Ready: 4, Required: 4, Updated: 4},
+		},
+		{
+			kind:   KindDaemonSet,
+			path:   "/default/api",
+			exists: false,
+		},
+	}
+
+	for _, item := range table {
+		w := &Workload{Client: &Client{Logger: logger, Config: c, clientset: clientset}, kind: item.kind}
+
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+		})
+		app.Get("/:namespace/:name", func(ctx *fiber.Ctx) error {
+			exists, err := w.IsServiceExists(ctx)
+			assert.NoError(t, err, item.path)
+			assert.Equal(t, item.exists, exists, item.path)
+			if !exists {
+				return nil
+			}
+
+			healthy, err := w.IsServiceHealthy(ctx)
+			assert.NoError(t, err, item.path)
+			assert.Equal(t, item.healthy, healthy, item.path)
+
+			status, _ := endpoints.Get(ctx)
+			assert.Equal(t, item.expected, status, item.path)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest("GET", "http://localhost"+item.path, nil))
+		assert.NoError(t, err)
+	}
+}
+
+func TestNewWorkload(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewWorkload(&Clients{}, "replicaset")
+	assert.Error(t, err)
+}
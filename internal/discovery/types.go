@@ -3,13 +3,24 @@ package discovery
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/k8s"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
 	"go.uber.org/zap"
 )
 
 type Discovery struct {
-	Logger *zap.Logger
-	Config *config.Config
-	Source string
+	Logger  *zap.Logger
+	Config  *config.Config
+	Metrics *metrics.Metrics
+	Source  string
+	// Kind is the kind of workloads or routes evaluated by the kubernetes-workload
+	// and kubernetes-route sources.
+	Kind string
+	// Clusters selects the configured Kubernetes cluster by the cluster path parameter.
+	Clusters bool
+	// Kubernetes shares Kubernetes clients between adapters, a client is created for
+	// each adapter if nil.
+	Kubernetes *k8s.Clients
 }
 
 type Adapter interface {
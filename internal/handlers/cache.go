@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/scheduler"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// internalKey marks requests dispatched by the cache to refresh a result.
+	internalKey = "healthgroup.internal"
+	// resultKey is used to pass the result of an internal request back to the cache.
+	resultKey = "healthgroup.result"
+)
+
+// Cache serves results of discovery targets that are evaluated in the background
+// by the scheduler. A target is scheduled when it's requested for the first time.
+type Cache struct {
+	Scheduler *scheduler.Scheduler
+	Interval  time.Duration
+
+	handler fasthttp.RequestHandler
+}
+
+// NewCache creates a cache that refreshes results every interval.
+func NewCache(s *scheduler.Scheduler, interval time.Duration) *Cache {
+	return &Cache{
+		Scheduler: s,
+		Interval:  interval,
+	}
+}
+
+// SetHandler sets the app handler used to evaluate targets in the background.
+func (c *Cache) SetHandler(handler fasthttp.RequestHandler) {
+	if c == nil {
+		return
+	}
+
+	c.handler = handler
+}
+
+// IsInternal returns true if the request has been dispatched by the cache.
+func IsInternal(c *fiber.Ctx) bool {
+	internal, _ := c.Context().UserValue(internalKey).(bool)
+	return internal
+}
+
+// get returns the latest result of the target requested by the context.
+func (c *Cache) get(ctx *fiber.Ctx) *result {
+	uri := cacheKey(ctx)
+
+	value, updated := c.Scheduler.Get("target/"+uri, c.Interval, func() interface{} {
+		return c.dispatch(uri)
+	})
+
+	r := *value.(*result) //nolint:forcetypeassert
+	r.time = updated
+	r.age = time.Since(updated)
+
+	return &r
+}
+
+// dispatch evaluates the target by sending an internal request to the app.
+func (c *Cache) dispatch(uri string) *result {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fiber.MethodGet)
+	ctx.Request.SetRequestURI(uri)
+	ctx.SetUserValue(internalKey, true)
+
+	c.handler(ctx)
+
+	if r, ok := ctx.UserValue(resultKey).(*result); ok {
+		return r
+	}
+
+	return &result{
+		status:  ctx.Response.StatusCode(),
+		message: string(ctx.Response.Body()),
+		time:    time.Now(),
+	}
+}
+
+// targetParams are the query parameters that select or evaluate a discovery target.
+// Other parameters, e.g. verbose, don't change the result of the target.
+var targetParams = map[string]bool{
+	"dc":                true,
+	"failover":          true,
+	"failoverPolicy":    true,
+	"filter":            true,
+	"local":             true,
+	"minPassing":        true,
+	"minPassingPercent": true,
+	"minReady":          true,
+	"minUpdated":        true,
+	"partition":         true,
+	"peer":              true,
+	"policy":            true,
+	"port":              true,
+	"probe":             true,
+	"probePath":         true,
+	"probePort":         true,
+	"selector":          true,
+	"tag":               true,
+	"warningAsPassing":  true,
+	"zone":              true,
+}
+
+// cacheKey returns the request path along with sorted target parameters, so unknown
+// parameters don't create new targets.
+func cacheKey(c *fiber.Ctx) string {
+	var args []string
+
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if !targetParams[string(key)] {
+			return
+		}
+		args = append(args, string(key)+"="+string(value))
+	})
+
+	if len(args) == 0 {
+		return c.Path()
+	}
+
+	sort.Strings(args)
+
+	return c.Path() + "?" + strings.Join(args, "&")
+}
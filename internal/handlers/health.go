@@ -1,51 +1,259 @@
 package handlers
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/config"
 	"github.com/tczekajlo/healthgroup/internal/discovery"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
 	"github.com/tczekajlo/healthgroup/internal/healthcheck"
 )
 
-func Health(c *fiber.Ctx, healthCheck *healthcheck.HealthCheck, discovery discovery.Adapter) error {
+// result holds the outcome of the discovery and auxiliary health checks for a single request.
+type result struct {
+	status int
+	// passed is set if all critical checks passed, degraded if any non-critical check failed.
+	passed    bool
+	degraded  bool
+	message   string
+	discovery DiscoveryResult
+	report    *healthcheck.Report
+	// time is when the result was evaluated, age is set only for cached results.
+	time time.Time
+	age  time.Duration
+}
+
+func Health(c *fiber.Ctx, healthCheck *healthcheck.HealthCheck, discovery discovery.Adapter, cache *Cache) error {
+	if cache == nil {
+		return respond(c, healthCheck.Config, evaluate(c, healthCheck, discovery))
+	}
+
+	if IsInternal(c) {
+		c.Context().SetUserValue(resultKey, evaluate(c, healthCheck, discovery))
+		return nil
+	}
+
+	return respond(c, healthCheck.Config, cache.get(c))
+}
+
+func evaluate(c *fiber.Ctx, healthCheck *healthcheck.HealthCheck, discovery discovery.Adapter) *result {
+	r := &result{
+		time: time.Now(),
+		discovery: DiscoveryResult{
+			Source:    healthCheck.Discovery,
+			Cluster:   c.Params("cluster"),
+			Namespace: c.Params("namespace"),
+			Service:   healthcheck.Service(c),
+		},
+	}
+
 	exist, err := discovery.IsServiceExists(c)
 	if err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(ResponseHTTP{
-			Success: false,
-			Message: err.Error(),
-		})
+		r.discovery.Error = err.Error()
+		return r.fail(fiber.StatusServiceUnavailable, err.Error())
 	}
 
+	r.discovery.Exists = exist
 	if !exist {
-		return c.Status(fiber.StatusNotFound).JSON(ResponseHTTP{
-			Success: false,
-			Message: "Service not found",
-		})
+		return r.fail(fiber.StatusNotFound, "Service not found")
 	}
 
 	healthy, err := discovery.IsServiceHealthy(c)
 	if err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(ResponseHTTP{
-			Success: false,
-			Message: err.Error(),
-		})
+		r.discovery.Error = err.Error()
+		return r.fail(fiber.StatusServiceUnavailable, err.Error())
+	}
+
+	if status, ok := endpoints.Get(c); ok {
+		r.discovery.Datacenter = status.Datacenter
+		r.discovery.Node = status.Node
+		r.discovery.Zone = status.Zone
+		r.discovery.Endpoints = newEndpointsResult(status)
+
+		for _, backend := range status.Backends {
+			b := BackendResult{
+				Namespace: backend.Namespace,
+				Service:   backend.Service,
+				Port:      backend.Port,
+				Exists:    backend.Exists,
+				Healthy:   backend.Healthy,
+			}
+			if backend.Exists {
+				b.Endpoints = newEndpointsResult(backend.Status)
+			}
+			r.discovery.Backends = append(r.discovery.Backends, b)
+		}
 	}
 
+	rise, fall := discoveryThresholds(healthCheck.Config, healthCheck.Discovery)
+	// The path along with query parameters identifies the discovery target, e.g. workloads
+	// of different kinds or pods of different selectors.
+	key := "discovery" + cacheKey(c)
+	healthy = healthCheck.Tracker.Observe(key, healthy, rise, fall)
+
+	r.discovery.Healthy = healthy
 	if !healthy {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(ResponseHTTP{
-			Success: false,
-			Message: "Service is not healthy",
-		})
+		return r.fail(fiber.StatusServiceUnavailable, "Service is not healthy")
+	}
+
+	r.report, err = healthCheck.Run(c)
+	if err != nil {
+		return r.fail(fiber.StatusServiceUnavailable, err.Error())
+	}
+
+	r.passed = true
+
+	if r.report.Degraded() {
+		r.degraded = true
+		r.status = healthCheck.Config.Server.DegradedStatusCode
+		r.message = "non-critical health checks failed"
+
+		return r
+	}
+
+	r.status = fiber.StatusOK
+	r.message = "all health checks passed"
+
+	return r
+}
+
+func newEndpointsResult(status endpoints.Status) *EndpointsResult {
+	return &EndpointsResult{
+		Ready:           status.Ready,
+		NotReady:        status.NotReady,
+		Terminating:     status.Terminating,
+		Warning:         status.Warning,
+		Required:        status.Required,
+		Updated:         status.Updated,
+		RequiredUpdated: status.RequiredUpdated,
+	}
+}
+
+// discoveryThresholds returns the rise and fall thresholds of the discovery source.
+func discoveryThresholds(config *config.Config, source string) (int, int) {
+	switch source {
+	case discovery.Kubernetes, discovery.KubernetesSelector, discovery.KubernetesWorkload, discovery.KubernetesRoute:
+		return config.Kubernetes.Rise, config.Kubernetes.Fall
+	case discovery.Consul, discovery.ConsulQuery:
+		return config.Consul.Rise, config.Consul.Fall
+	}
+
+	return 0, 0
+}
+
+func respond(c *fiber.Ctx, config *config.Config, r *result) error {
+	if r.age > 0 {
+		c.Set(fiber.HeaderAge, strconv.Itoa(int(r.age.Seconds())))
+	}
+	c.Set(HeaderHealthStatus, r.healthStatus())
+
+	if c.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationHealthJSON) == MIMEApplicationHealthJSON {
+		return respondHealthJSON(c, r)
+	}
+
+	resp := ResponseHTTP{
+		Success:  r.passed,
+		Degraded: r.degraded,
+		Message:  r.message,
+	}
+
+	if r.age > 0 {
+		resp.Age = r.age.Round(time.Millisecond).String()
+	}
+
+	if isVerbose(c, config) {
+		resp.Details = newDetails(r)
+	}
+
+	return c.Status(r.status).JSON(resp)
+}
+
+// healthStatus returns the overall status: pass, warn if degraded, or fail.
+func (r *result) healthStatus() string {
+	switch {
+	case r.degraded:
+		return HealthStatusWarn
+	case r.passed:
+		return HealthStatusPass
+	}
+
+	return HealthStatusFail
+}
+
+func (r *result) fail(status int, message string) *result {
+	r.status = status
+	r.message = message
+
+	return r
+}
+
+// isVerbose returns true if the detailed response is requested by the verbose
+// query parameter or enabled in the configuration.
+func isVerbose(c *fiber.Ctx, config *config.Config) bool {
+	args := c.Context().QueryArgs()
+	if args.Has("verbose") && len(args.Peek("verbose")) == 0 {
+		return true
+	}
+
+	return c.QueryBool("verbose", config.Server.Verbose)
+}
+
+func newDetails(r *result) *Details {
+	details := &Details{
+		Discovery: r.discovery,
+	}
+
+	if r.report == nil {
+		return details
+	}
+
+	for _, res := range r.report.Results {
+		check := CheckResult{
+			Name:       res.Name,
+			Type:       res.Type,
+			Target:     res.Target,
+			Severity:   res.Severity,
+			Group:      res.Group,
+			StatusCode: res.StatusCode,
+			Duration:   res.Duration.String(),
+			Passed:     res.Passed,
+		}
+		if res.Age != 0 {
+			check.Age = res.Age.String()
+		}
+		if res.Err != nil {
+			check.Error = res.Err.Error()
+		}
+		details.Checks = append(details.Checks, check)
+	}
+
+	for _, res := range r.report.Groups {
+		group := CheckGroupResult{
+			Name:     res.Name,
+			Severity: res.Severity,
+			Required: res.Required,
+			Passing:  res.Passing,
+			Total:    res.Total,
+			Passed:   res.Passed,
+			Members:  res.Members,
+			Failed:   res.Failed,
+		}
+		if res.Err != nil {
+			group.Error = res.Err.Error()
+		}
+		details.Groups = append(details.Groups, group)
 	}
 
-	if err := healthCheck.Run(c); err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(ResponseHTTP{
-			Success: false,
-			Message: err.Error(),
+	for _, res := range r.report.Skipped {
+		details.Skipped = append(details.Skipped, SkippedCheck{
+			Name:   res.Name,
+			Type:   res.Type,
+			Target: res.Target,
+			Reason: res.Reason,
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(ResponseHTTP{
-		Success: true,
-		Message: "all health checks passed",
-	})
+	return details
 }
@@ -12,23 +12,76 @@ import (
 // @Summary Run health checks
 // @Description Run health checks
 // @Produce json
+// @Produce application/health+json
 // @Param service path string true "Consul service"
 // @Param namespace path string false "Consul namespace"
 // @Success 200 {object} ResponseHTTP{}
 // @Failure 503 {object} ResponseHTTP{}
 // @Router /health/consul/{service} [get]
 // @Router /health/consul/{namespace}/{service} [get]
-func HealthConsul(config *config.Config, logger *zap.Logger) fiber.Handler {
+func HealthConsul(config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	o := newOptions(opts...)
+
 	h := &healthcheck.HealthCheck{
 		Logger:    logger,
 		Config:    config,
+		Metrics:   o.metrics,
+		Tracker:   o.tracker,
 		Discovery: discovery.Consul,
 	}
+	if o.cache != nil {
+		h.Scheduler = o.cache.Scheduler
+	}
+
+	d, err := discovery.New(&discovery.Discovery{
+		Logger:  logger,
+		Config:  config,
+		Metrics: o.metrics,
+		Source:  discovery.Consul,
+	})
+	if err != nil {
+		return func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(ResponseHTTP{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+	}
+	defer d.Close()
+
+	return func(c *fiber.Ctx) error {
+		return Health(c, h, d, o.cache)
+	}
+}
+
+// HealthConsulQuery is a function to run a health check for the instances returned by a Consul prepared query along with extra checks defined in the configuration file.
+// @Summary Run health checks
+// @Description Run health checks
+// @Produce json
+// @Produce application/health+json
+// @Param query path string true "Consul prepared query name or ID"
+// @Success 200 {object} ResponseHTTP{}
+// @Failure 503 {object} ResponseHTTP{}
+// @Router /health/consul-query/{query} [get]
+func HealthConsulQuery(config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	o := newOptions(opts...)
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    config,
+		Metrics:   o.metrics,
+		Tracker:   o.tracker,
+		Discovery: discovery.ConsulQuery,
+	}
+	if o.cache != nil {
+		h.Scheduler = o.cache.Scheduler
+	}
 
 	d, err := discovery.New(&discovery.Discovery{
-		Logger: logger,
-		Config: config,
-		Source: discovery.Consul,
+		Logger:  logger,
+		Config:  config,
+		Metrics: o.metrics,
+		Source:  discovery.ConsulQuery,
 	})
 	if err != nil {
 		return func(c *fiber.Ctx) error {
@@ -41,6 +94,6 @@ func HealthConsul(config *config.Config, logger *zap.Logger) fiber.Handler {
 	defer d.Close()
 
 	return func(c *fiber.Ctx) error {
-		return Health(c, h, d)
+		return Health(c, h, d, o.cache)
 	}
 }
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/healthcheck"
+	"github.com/tczekajlo/healthgroup/internal/version"
+)
+
+// MIMEApplicationHealthJSON is the media type of the health check response format
+// for HTTP APIs (draft-inadarei-api-health-check).
+const MIMEApplicationHealthJSON = "application/health+json"
+
+// HeaderHealthStatus is the response header with the overall status: pass, warn or fail.
+const HeaderHealthStatus = "X-Health-Status"
+
+const (
+	HealthStatusPass = "pass"
+	HealthStatusWarn = "warn"
+	HealthStatusFail = "fail"
+)
+
+// respondHealthJSON writes the result in the application/health+json format.
+func respondHealthJSON(c *fiber.Ctx, r *result) error {
+	body, err := c.App().Config().JSONEncoder(newResponseHealthJSON(c, r))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, MIMEApplicationHealthJSON)
+
+	return c.Status(r.status).Send(body)
+}
+
+func newResponseHealthJSON(c *fiber.Ctx, r *result) ResponseHealthJSON {
+	evaluated := r.time.UTC().Format(time.RFC3339)
+	resp := ResponseHealthJSON{
+		Status:    r.healthStatus(),
+		Version:   version.Version,
+		ServiceID: strings.TrimPrefix(c.Path(), "/health/"),
+		Checks:    map[string][]HealthJSONCheck{},
+	}
+
+	if r.status != fiber.StatusOK || r.degraded {
+		resp.Output = r.message
+	}
+
+	discoveryCheck := HealthJSONCheck{
+		ComponentID:   strings.TrimPrefix(fmt.Sprintf("%s/%s", r.discovery.Namespace, r.discovery.Service), "/"),
+		ComponentType: "component",
+		Status:        healthStatus(r.discovery.Exists && r.discovery.Healthy),
+		Time:          evaluated,
+		Output:        r.discovery.Error,
+	}
+	if r.discovery.Endpoints != nil {
+		discoveryCheck.ObservedValue = r.discovery.Endpoints.Ready
+		discoveryCheck.ObservedUnit = "ready endpoints"
+	}
+	if !r.discovery.Exists && r.discovery.Error == "" {
+		discoveryCheck.Output = "Service not found"
+	}
+	resp.Checks[fmt.Sprintf("%s:service", r.discovery.Source)] = []HealthJSONCheck{discoveryCheck}
+
+	for _, backend := range r.discovery.Backends {
+		check := HealthJSONCheck{
+			ComponentID:   fmt.Sprintf("%s/%s", backend.Namespace, backend.Service),
+			ComponentType: "component",
+			Status:        healthStatus(backend.Healthy),
+			Time:          evaluated,
+		}
+		if backend.Endpoints != nil {
+			check.ObservedValue = backend.Endpoints.Ready
+			check.ObservedUnit = "ready endpoints"
+		}
+		if !backend.Exists {
+			check.Output = "Service not found"
+		}
+
+		key := fmt.Sprintf("%s:backend", r.discovery.Source)
+		resp.Checks[key] = append(resp.Checks[key], check)
+	}
+
+	if r.report == nil {
+		return resp
+	}
+
+	for _, res := range r.report.Results {
+		name := res.Name
+		if name == "" {
+			name = res.Target
+		}
+
+		check := HealthJSONCheck{
+			ComponentID:   res.Target,
+			ComponentType: res.Type,
+			ObservedValue: float64(res.Duration) / float64(time.Millisecond),
+			ObservedUnit:  "ms",
+			Status:        checkStatus(res),
+			Time:          res.Time.UTC().Format(time.RFC3339),
+		}
+		if res.Err != nil {
+			check.Output = res.Err.Error()
+		}
+
+		key := fmt.Sprintf("%s:responseTime", name)
+		resp.Checks[key] = append(resp.Checks[key], check)
+	}
+
+	return resp
+}
+
+// checkStatus returns the status of a check, failed non-critical checks only warn.
+func checkStatus(res healthcheck.Result) string {
+	if !res.Passed && res.Severity == healthcheck.SeverityWarning {
+		return HealthStatusWarn
+	}
+
+	return healthStatus(res.Passed)
+}
+
+func healthStatus(passed bool) string {
+	if passed {
+		return HealthStatusPass
+	}
+
+	return HealthStatusFail
+}
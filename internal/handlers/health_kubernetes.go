@@ -12,23 +12,99 @@ import (
 // @Summary Run health checks
 // @Description Run health checks
 // @Produce json
+// @Produce application/health+json
 // @Param namespace path string true "Kubernetes namespace"
 // @Param service path string true "Kubernetes service"
 // @Success 200 {object} ResponseHTTP{}
 // @Failure 503 {object} ResponseHTTP{}
 // @Router /health/kubernetes/{namespace}/{service} [get]
-func HealthKubernetes(config *config.Config, logger *zap.Logger) fiber.Handler {
+func HealthKubernetes(config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	return healthKubernetes(discovery.Discovery{Source: discovery.Kubernetes}, config, logger, opts...)
+}
+
+// HealthKubernetesCluster is a function to run health check for a Kubernetes service of a configured cluster along with extra checks defined in the configuration file.
+// @Summary Run health checks
+// @Description Run health checks
+// @Produce json
+// @Produce application/health+json
+// @Param cluster path string true "Kubernetes cluster"
+// @Param namespace path string true "Kubernetes namespace"
+// @Param service path string true "Kubernetes service"
+// @Success 200 {object} ResponseHTTP{}
+// @Failure 503 {object} ResponseHTTP{}
+// @Router /health/kubernetes-cluster/{cluster}/{namespace}/{service} [get]
+func HealthKubernetesCluster(config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	return healthKubernetes(discovery.Discovery{Source: discovery.Kubernetes, Clusters: true}, config, logger, opts...)
+}
+
+// HealthKubernetesSelector is a function to run health check for Kubernetes pods selected by a label selector along with extra checks defined in the configuration file.
+// @Summary Run health checks
+// @Description Run health checks
+// @Produce json
+// @Produce application/health+json
+// @Param namespace path string true "Kubernetes namespace"
+// @Param selector query string true "Label selector"
+// @Success 200 {object} ResponseHTTP{}
+// @Failure 503 {object} ResponseHTTP{}
+// @Router /health/kubernetes-selector/{namespace} [get]
+func HealthKubernetesSelector(config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	return healthKubernetes(discovery.Discovery{Source: discovery.KubernetesSelector}, config, logger, opts...)
+}
+
+// HealthKubernetesWorkload is a function to run health check for the rollout state of a Kubernetes workload along with extra checks defined in the configuration file.
+// @Summary Run health checks
+// @Description Run health checks
+// @Produce json
+// @Produce application/health+json
+// @Param namespace path string true "Kubernetes namespace"
+// @Param name path string true "Workload name"
+// @Success 200 {object} ResponseHTTP{}
+// @Failure 503 {object} ResponseHTTP{}
+// @Router /health/kubernetes/{namespace}/deployment/{name} [get]
+// @Router /health/kubernetes/{namespace}/statefulset/{name} [get]
+// @Router /health/kubernetes/{namespace}/daemonset/{name} [get]
+func HealthKubernetesWorkload(kind string, config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	return healthKubernetes(discovery.Discovery{Source: discovery.KubernetesWorkload, Kind: kind}, config, logger, opts...)
+}
+
+// HealthKubernetesRoute is a function to run health check for backend services of a Kubernetes Ingress or HTTPRoute along with extra checks defined in the configuration file.
+// @Summary Run health checks
+// @Description Run health checks
+// @Produce json
+// @Produce application/health+json
+// @Param namespace path string true "Kubernetes namespace"
+// @Param name path string true "Route name"
+// @Param policy query string false "Backend policy: all, any, quorum"
+// @Success 200 {object} ResponseHTTP{}
+// @Failure 503 {object} ResponseHTTP{}
+// @Router /health/kubernetes/{namespace}/ingress/{name} [get]
+// @Router /health/kubernetes/{namespace}/httproute/{name} [get]
+func HealthKubernetesRoute(kind string, config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	return healthKubernetes(discovery.Discovery{Source: discovery.KubernetesRoute, Kind: kind}, config, logger, opts...)
+}
+
+// healthKubernetes returns the handler of a Kubernetes route, variant selects the source
+// of the discovery and its kind or clusters.
+func healthKubernetes(variant discovery.Discovery, config *config.Config, logger *zap.Logger, opts ...Option) fiber.Handler {
+	o := newOptions(opts...)
+
 	h := &healthcheck.HealthCheck{
 		Logger:    logger,
 		Config:    config,
-		Discovery: discovery.Kubernetes,
+		Metrics:   o.metrics,
+		Tracker:   o.tracker,
+		Discovery: variant.Source,
 	}
+	if o.cache != nil {
+		h.Scheduler = o.cache.Scheduler
+	}
+
+	variant.Logger = logger
+	variant.Config = config
+	variant.Metrics = o.metrics
+	variant.Kubernetes = o.k8s
 
-	d, err := discovery.New(&discovery.Discovery{
-		Logger: logger,
-		Config: config,
-		Source: discovery.Kubernetes,
-	})
+	d, err := discovery.New(&variant)
 	if err != nil {
 		return func(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(ResponseHTTP{
@@ -40,6 +116,6 @@ func HealthKubernetes(config *config.Config, logger *zap.Logger) fiber.Handler {
 	defer d.Close()
 
 	return func(c *fiber.Ctx) error {
-		return Health(c, h, d)
+		return Health(c, h, d, o.cache)
 	}
 }
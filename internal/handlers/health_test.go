@@ -1,16 +1,25 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/stretchr/testify/assert"
 	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery"
+	"github.com/tczekajlo/healthgroup/internal/discovery/endpoints"
+	"github.com/tczekajlo/healthgroup/internal/healthcheck"
+	"github.com/tczekajlo/healthgroup/internal/hysteresis"
 	"github.com/tczekajlo/healthgroup/internal/log"
+	"github.com/tczekajlo/healthgroup/internal/scheduler"
 )
 
 func TestRoute(t *testing.T) {
@@ -35,6 +44,7 @@ func TestRoute(t *testing.T) {
 	app.Use(requestid.New())
 
 	app.Get("/health/kubernetes/:namespace/:service", HealthKubernetes(config, logger))
+	app.Get("/health/kubernetes-cluster/:cluster/:namespace/:service", HealthKubernetesCluster(config, logger))
 	app.Get("/health/consul/:namespace/:service", HealthConsul(config, logger))
 	app.Get("/health/consul/:service", HealthConsul(config, logger))
 
@@ -58,6 +68,11 @@ func TestRoute(t *testing.T) {
 			path:         "/health/kubernetes/testns/testservice",
 			expectedCode: fiber.StatusServiceUnavailable,
 		},
+		{
+			desc:         "health check for Kubernetes cluster that isn't configured",
+			path:         "/health/kubernetes-cluster/unknown/testns/testservice",
+			expectedCode: fiber.StatusNotFound,
+		},
 	}
 
 	for _, item := range table {
@@ -69,3 +84,662 @@ func TestRoute(t *testing.T) {
 		})
 	}
 }
+
+type fakeAdapter struct {
+	exists    bool
+	healthy   bool
+	endpoints *endpoints.Status
+}
+
+func (f *fakeAdapter) IsServiceExists(_ *fiber.Ctx) (bool, error) {
+	return f.exists, nil
+}
+
+func (f *fakeAdapter) IsServiceHealthy(c *fiber.Ctx) (bool, error) {
+	if f.endpoints != nil {
+		endpoints.Set(c, *f.endpoints)
+	}
+	return f.healthy, nil
+}
+
+func (f *fakeAdapter) Close() {}
+
+func TestHealthVerbose(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	c.TCPHealthCheck = []config.TCPHealthCheck{
+		{Name: "listener", Host: "127.0.0.1", Port: p, Timeout: time.Second},
+		{Name: "other", Host: "127.0.0.1", Port: p, Service: "other"},
+	}
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Discovery: discovery.Kubernetes,
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/healthy/:namespace/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, &fakeAdapter{exists: true, healthy: true}, nil)
+	})
+	app.Get("/unhealthy/:namespace/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, &fakeAdapter{exists: true, healthy: false}, nil)
+	})
+
+	table := []struct {
+		desc         string
+		path         string
+		expectedCode int
+		expected     ResponseHTTP
+	}{
+		{
+			desc:         "not verbose",
+			path:         "/healthy/ns/api",
+			expectedCode: fiber.StatusOK,
+			expected: ResponseHTTP{
+				Success: true,
+				Message: "all health checks passed",
+			},
+		},
+		{
+			desc:         "verbose",
+			path:         "/healthy/ns/api?verbose",
+			expectedCode: fiber.StatusOK,
+			expected: ResponseHTTP{
+				Success: true,
+				Message: "all health checks passed",
+				Details: &Details{
+					Discovery: DiscoveryResult{
+						Source:    discovery.Kubernetes,
+						Namespace: "ns",
+						Service:   "api",
+						Exists:    true,
+						Healthy:   true,
+					},
+					Checks: []CheckResult{
+						{Name: "listener", Type: "tcp", Target: ln.Addr().String(), Severity: "critical", Passed: true},
+					},
+					Skipped: []SkippedCheck{
+						{Name: "other", Type: "tcp", Target: ln.Addr().String(), Reason: "service doesn't match: other"},
+					},
+				},
+			},
+		},
+		{
+			desc:         "verbose unhealthy service",
+			path:         "/unhealthy/ns/api?verbose=true",
+			expectedCode: fiber.StatusServiceUnavailable,
+			expected: ResponseHTTP{
+				Success: false,
+				Message: "Service is not healthy",
+				Details: &Details{
+					Discovery: DiscoveryResult{
+						Source:    discovery.Kubernetes,
+						Namespace: "ns",
+						Service:   "api",
+						Exists:    true,
+						Healthy:   false,
+					},
+				},
+			},
+		},
+	}
+
+	for _, item := range table {
+		t.Run(item.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost%s", item.path), nil)
+			resp, _ := app.Test(req)
+
+			var body ResponseHTTP
+			err := json.NewDecoder(resp.Body).Decode(&body)
+			assert.Empty(t, err)
+
+			// Duration isn't deterministic.
+			if body.Details != nil {
+				for i := range body.Details.Checks {
+					body.Details.Checks[i].Duration = ""
+				}
+			}
+
+			assert.Equal(t, item.expectedCode, resp.StatusCode)
+			assert.Equal(t, item.expected, body)
+		})
+	}
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Discovery: discovery.Kubernetes,
+	}
+
+	adapter := &fakeAdapter{
+		exists:    true,
+		healthy:   false,
+		endpoints: &endpoints.Status{Ready: 1, NotReady: 2, Required: 2, Datacenter: "dc2", Node: "node-a", Zone: "zone-a"},
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/kubernetes/:namespace/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, adapter, nil)
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/api?verbose", nil)
+	resp, _ := app.Test(req)
+
+	var body ResponseHTTP
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	assert.Empty(t, err)
+
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, &EndpointsResult{Ready: 1, NotReady: 2, Required: 2}, body.Details.Discovery.Endpoints)
+	assert.Equal(t, "dc2", body.Details.Discovery.Datacenter)
+	assert.Equal(t, "node-a", body.Details.Discovery.Node)
+	assert.Equal(t, "zone-a", body.Details.Discovery.Zone)
+
+	req = httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/api", nil)
+	req.Header.Set(fiber.HeaderAccept, MIMEApplicationHealthJSON)
+	resp, _ = app.Test(req)
+
+	var healthJSON ResponseHealthJSON
+	err = json.NewDecoder(resp.Body).Decode(&healthJSON)
+	assert.Empty(t, err)
+
+	assert.Equal(t, float64(1), healthJSON.Checks["kubernetes:service"][0].ObservedValue)
+}
+
+func TestHealthBackends(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Discovery: discovery.KubernetesRoute,
+	}
+
+	adapter := &fakeAdapter{
+		exists:  true,
+		healthy: false,
+		endpoints: &endpoints.Status{
+			Ready:    1,
+			NotReady: 1,
+			Required: 2,
+			Backends: []endpoints.Backend{
+				{Namespace: "default", Service: "web", Port: "80", Exists: true, Healthy: true, Status: endpoints.Status{Ready: 2, Required: 1}},
+				{Namespace: "default", Service: "api", Port: "http"},
+			},
+		},
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/kubernetes/:namespace/ingress/:name", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, adapter, nil)
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/ingress/shop?verbose", nil)
+	resp, _ := app.Test(req)
+
+	var body ResponseHTTP
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	assert.Empty(t, err)
+
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "shop", body.Details.Discovery.Service)
+	assert.Equal(t, []BackendResult{
+		{Namespace: "default", Service: "web", Port: "80", Exists: true, Healthy: true, Endpoints: &EndpointsResult{Ready: 2, Required: 1}},
+		{Namespace: "default", Service: "api", Port: "http"},
+	}, body.Details.Discovery.Backends)
+
+	req = httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/ingress/shop", nil)
+	req.Header.Set(fiber.HeaderAccept, MIMEApplicationHealthJSON)
+	resp, _ = app.Test(req)
+
+	var healthJSON ResponseHealthJSON
+	err = json.NewDecoder(resp.Body).Decode(&healthJSON)
+	assert.Empty(t, err)
+
+	backends := healthJSON.Checks["kubernetes-route:backend"]
+	assert.Len(t, backends, 2)
+	assert.Equal(t, "default/web", backends[0].ComponentID)
+	assert.Equal(t, HealthStatusPass, backends[0].Status)
+	assert.Equal(t, HealthStatusFail, backends[1].Status)
+	assert.Equal(t, "Service not found", backends[1].Output)
+}
+
+func TestHealthDegraded(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	// Nothing listens on the port once the listener is closed.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+	c.Server.DegradedStatusCode = fiber.StatusMultiStatus
+
+	c.TCPHealthCheck = []config.TCPHealthCheck{
+		{Name: "cache", Host: "127.0.0.1", Port: addr.Port, Timeout: time.Second, Severity: "warning", Service: "api"},
+		{Name: "database", Host: "127.0.0.1", Port: addr.Port, Timeout: time.Second, Service: "worker"},
+	}
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Discovery: discovery.Kubernetes,
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/kubernetes/:namespace/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, &fakeAdapter{exists: true, healthy: true}, nil)
+	})
+
+	t.Run("warning", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/api", nil)
+		resp, _ := app.Test(req)
+
+		var body ResponseHTTP
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		assert.Empty(t, err)
+
+		assert.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+		assert.Equal(t, HealthStatusWarn, resp.Header.Get(HeaderHealthStatus))
+		assert.True(t, body.Success)
+		assert.True(t, body.Degraded)
+	})
+
+	t.Run("warning health+json", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/api", nil)
+		req.Header.Set(fiber.HeaderAccept, MIMEApplicationHealthJSON)
+		resp, _ := app.Test(req)
+
+		var body ResponseHealthJSON
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		assert.Empty(t, err)
+
+		assert.Equal(t, HealthStatusWarn, body.Status)
+		assert.Equal(t, HealthStatusWarn, body.Checks["cache:responseTime"][0].Status)
+	})
+
+	t.Run("critical", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/worker", nil)
+		resp, _ := app.Test(req)
+
+		var body ResponseHTTP
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		assert.Empty(t, err)
+
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, HealthStatusFail, resp.Header.Get(HeaderHealthStatus))
+		assert.False(t, body.Success)
+		assert.False(t, body.Degraded)
+	})
+}
+
+func TestHealthCheckGroup(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// Nothing listens on the port once the listener is closed.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	c.CheckGroups = []config.CheckGroup{
+		{Name: "mirrors", MinPassing: "60%"},
+	}
+	c.TCPHealthCheck = []config.TCPHealthCheck{
+		{Name: "mirror-a", Host: "127.0.0.1", Port: port, Timeout: time.Second, Group: "mirrors"},
+		{Name: "mirror-b", Host: "127.0.0.1", Port: port, Timeout: time.Second, Group: "mirrors"},
+		{Name: "mirror-c", Host: "127.0.0.1", Port: closedPort, Timeout: time.Second, Group: "mirrors"},
+		{Name: "mirror-d", Host: "127.0.0.1", Port: closedPort, Timeout: time.Second, Group: "mirrors", Service: "worker"},
+	}
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Discovery: discovery.Kubernetes,
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/kubernetes/:namespace/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, &fakeAdapter{exists: true, healthy: true}, nil)
+	})
+
+	table := []struct {
+		desc         string
+		path         string
+		expectedCode int
+		expected     CheckGroupResult
+	}{
+		{
+			desc:         "quorum met",
+			path:         "/health/kubernetes/default/api?verbose",
+			expectedCode: fiber.StatusOK,
+			expected: CheckGroupResult{
+				Name:     "mirrors",
+				Severity: "critical",
+				Required: 2,
+				Passing:  2,
+				Total:    3,
+				Passed:   true,
+				Members:  []string{"mirror-a", "mirror-b", "mirror-c"},
+				Failed:   []string{"mirror-c"},
+			},
+		},
+		{
+			desc:         "quorum not met",
+			path:         "/health/kubernetes/default/worker?verbose",
+			expectedCode: fiber.StatusServiceUnavailable,
+			expected: CheckGroupResult{
+				Name:     "mirrors",
+				Severity: "critical",
+				Required: 3,
+				Passing:  2,
+				Total:    4,
+				Passed:   false,
+				Members:  []string{"mirror-a", "mirror-b", "mirror-c", "mirror-d"},
+				Failed:   []string{"mirror-c", "mirror-d"},
+				Error:    "check group mirrors failed, 2 of 4 checks passed, required: 3",
+			},
+		},
+	}
+
+	for _, item := range table {
+		t.Run(item.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost%s", item.path), nil)
+			resp, _ := app.Test(req)
+
+			var body ResponseHTTP
+			err := json.NewDecoder(resp.Body).Decode(&body)
+			assert.Empty(t, err)
+
+			assert.Equal(t, item.expectedCode, resp.StatusCode)
+			assert.Equal(t, []CheckGroupResult{item.expected}, body.Details.Groups)
+		})
+	}
+}
+
+func TestHealthJSON(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	c.TCPHealthCheck = []config.TCPHealthCheck{
+		{Name: "closed", Host: "127.0.0.1", Port: 1, Timeout: time.Second},
+	}
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Discovery: discovery.Consul,
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/consul/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, &fakeAdapter{exists: true, healthy: true}, nil)
+	})
+
+	t.Run("default format", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost/health/consul/redis", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+	})
+
+	t.Run("health+json format", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://localhost/health/consul/redis", nil)
+		req.Header.Set(fiber.HeaderAccept, "application/health+json, application/json;q=0.9")
+		resp, _ := app.Test(req)
+
+		var body ResponseHealthJSON
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		assert.Empty(t, err)
+
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, MIMEApplicationHealthJSON, resp.Header.Get(fiber.HeaderContentType))
+		assert.Equal(t, HealthStatusFail, body.Status)
+		assert.Equal(t, "consul/redis", body.ServiceID)
+		assert.NotEmpty(t, body.Output)
+		assert.Equal(t, HealthStatusPass, body.Checks["consul:service"][0].Status)
+		assert.Equal(t, "redis", body.Checks["consul:service"][0].ComponentID)
+		assert.Equal(t, HealthStatusFail, body.Checks["closed:responseTime"][0].Status)
+		assert.Equal(t, "tcp", body.Checks["closed:responseTime"][0].ComponentType)
+		assert.Equal(t, "ms", body.Checks["closed:responseTime"][0].ObservedUnit)
+	})
+}
+
+func TestHealthJSONCheckTime(t *testing.T) {
+	executed := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/consul/:service", func(ctx *fiber.Ctx) error {
+		return respondHealthJSON(ctx, &result{
+			status: fiber.StatusOK,
+			passed: true,
+			time:   time.Now(),
+			report: &healthcheck.Report{Results: []healthcheck.Result{
+				{Name: "redis", Type: "tcp", Target: "127.0.0.1:6379", Passed: true, Time: executed, Age: time.Minute},
+			}},
+		})
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "http://localhost/health/consul/redis", nil))
+
+	var body ResponseHealthJSON
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	assert.Empty(t, err)
+
+	// The time of a check is when it was executed, not when the response was rendered.
+	assert.Equal(t, "2023-01-02T03:04:05Z", body.Checks["redis:responseTime"][0].Time)
+}
+
+func TestHealthCache(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	s := scheduler.New(logger, 0, 0)
+	defer s.Stop()
+
+	cache := NewCache(s, time.Hour)
+	adapter := &fakeAdapter{exists: true, healthy: true}
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Scheduler: s,
+		Discovery: discovery.Consul,
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/consul/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, adapter, cache)
+	})
+	cache.SetHandler(app.Handler())
+
+	req := httptest.NewRequest("GET", "http://localhost/health/consul/redis", nil)
+	resp, _ := app.Test(req)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// The result is served from the cache until it's refreshed in the background.
+	adapter.healthy = false
+
+	req = httptest.NewRequest("GET", "http://localhost/health/consul/redis?verbose", nil)
+	resp, _ = app.Test(req)
+
+	var body ResponseHTTP
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	assert.Empty(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get(fiber.HeaderAge))
+	assert.NotEmpty(t, body.Age)
+	assert.True(t, body.Details.Discovery.Healthy)
+
+	// Unknown parameters don't create a new target.
+	req = httptest.NewRequest("GET", "http://localhost/health/consul/redis?nocache=123", nil)
+	resp, _ = app.Test(req)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Another target is evaluated separately.
+	req = httptest.NewRequest("GET", "http://localhost/health/consul/redis?tag=primary", nil)
+	resp, _ = app.Test(req)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestHealthRiseFall(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+	c.Kubernetes.Rise = 2
+	c.Kubernetes.Fall = 3
+
+	adapter := &fakeAdapter{exists: true, healthy: true}
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Tracker:   hysteresis.New(),
+		Discovery: discovery.Kubernetes,
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/kubernetes/:namespace/:service", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, adapter, nil)
+	})
+
+	table := []struct {
+		healthy  bool
+		expected int
+	}{
+		{healthy: true, expected: fiber.StatusOK},
+		{healthy: false, expected: fiber.StatusOK},
+		{healthy: false, expected: fiber.StatusOK},
+		{healthy: false, expected: fiber.StatusServiceUnavailable},
+		{healthy: true, expected: fiber.StatusServiceUnavailable},
+		{healthy: true, expected: fiber.StatusOK},
+	}
+
+	for i, test := range table {
+		adapter.healthy = test.healthy
+
+		req := httptest.NewRequest("GET", "http://localhost/health/kubernetes/default/api", nil)
+		resp, _ := app.Test(req)
+		assert.Equalf(t, test.expected, resp.StatusCode, "request %d", i)
+	}
+}
+
+func TestHealthRiseFallTargets(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+	c.Kubernetes.Rise = 2
+	c.Kubernetes.Fall = 3
+
+	adapter := &fakeAdapter{exists: true, healthy: true}
+
+	h := &healthcheck.HealthCheck{
+		Logger:    logger,
+		Config:    c,
+		Tracker:   hysteresis.New(),
+		Discovery: discovery.KubernetesSelector,
+	}
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/kubernetes-selector/:namespace", func(ctx *fiber.Ctx) error {
+		return Health(ctx, h, adapter, nil)
+	})
+
+	table := []struct {
+		selector string
+		healthy  bool
+		expected int
+	}{
+		{selector: "app%3Dweb", healthy: true, expected: fiber.StatusOK},
+		// Pods of another selector keep their own state.
+		{selector: "app%3Ddb", healthy: false, expected: fiber.StatusServiceUnavailable},
+		{selector: "app%3Dweb", healthy: false, expected: fiber.StatusOK},
+		{selector: "app%3Ddb", healthy: true, expected: fiber.StatusServiceUnavailable},
+		{selector: "app%3Ddb", healthy: true, expected: fiber.StatusOK},
+	}
+
+	for i, test := range table {
+		adapter.healthy = test.healthy
+
+		req := httptest.NewRequest("GET", "http://localhost/health/kubernetes-selector/default?selector="+test.selector, nil)
+		resp, _ := app.Test(req)
+		assert.Equalf(t, test.expected, resp.StatusCode, "request %d", i)
+	}
+}
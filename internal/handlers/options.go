@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"github.com/tczekajlo/healthgroup/internal/discovery/k8s"
+	"github.com/tczekajlo/healthgroup/internal/hysteresis"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
+)
+
+// Option configures health handlers.
+type Option func(o *options)
+
+type options struct {
+	metrics *metrics.Metrics
+	cache   *Cache
+	tracker *hysteresis.Tracker
+	k8s     *k8s.Clients
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithCache enables answering requests from results evaluated in the background.
+func WithCache(cache *Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithTracker enables the rise and fall thresholds, the state is kept by the tracker.
+func WithTracker(t *hysteresis.Tracker) Option {
+	return func(o *options) {
+		o.tracker = t
+	}
+}
+
+// WithKubernetes shares Kubernetes clients, along with their informers, between handlers.
+func WithKubernetes(clients *k8s.Clients) Option {
+	return func(o *options) {
+		o.k8s = clients
+	}
+}
@@ -2,6 +2,110 @@ package handlers
 
 // ResponseHTTP represents response body.
 type ResponseHTTP struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success  bool     `json:"success"`
+	Degraded bool     `json:"degraded,omitempty"`
+	Message  string   `json:"message"`
+	Age      string   `json:"age,omitempty"`
+	Details  *Details `json:"details,omitempty"`
+}
+
+// Details represents detailed results returned in the verbose response.
+type Details struct {
+	Discovery DiscoveryResult    `json:"discovery"`
+	Checks    []CheckResult      `json:"checks,omitempty"`
+	Groups    []CheckGroupResult `json:"groups,omitempty"`
+	Skipped   []SkippedCheck     `json:"skipped,omitempty"`
+}
+
+// DiscoveryResult represents the result of the discovery service.
+type DiscoveryResult struct {
+	Source     string           `json:"source"`
+	Cluster    string           `json:"cluster,omitempty"`
+	Namespace  string           `json:"namespace,omitempty"`
+	Service    string           `json:"service"`
+	Datacenter string           `json:"datacenter,omitempty"`
+	Node       string           `json:"node,omitempty"`
+	Zone       string           `json:"zone,omitempty"`
+	Exists     bool             `json:"exists"`
+	Healthy    bool             `json:"healthy"`
+	Endpoints  *EndpointsResult `json:"endpoints,omitempty"`
+	Backends   []BackendResult  `json:"backends,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// BackendResult represents the result of a backend service of a route.
+type BackendResult struct {
+	Namespace string           `json:"namespace"`
+	Service   string           `json:"service"`
+	Port      string           `json:"port,omitempty"`
+	Exists    bool             `json:"exists"`
+	Healthy   bool             `json:"healthy"`
+	Endpoints *EndpointsResult `json:"endpoints,omitempty"`
+}
+
+// EndpointsResult represents the number of endpoints of the service.
+type EndpointsResult struct {
+	Ready       int `json:"ready"`
+	NotReady    int `json:"notReady"`
+	Terminating int `json:"terminating"`
+	Warning     int `json:"warning"`
+	Required    int `json:"required"`
+	// Updated replicas are reported only for workloads.
+	Updated         int `json:"updated,omitempty"`
+	RequiredUpdated int `json:"requiredUpdated,omitempty"`
+}
+
+// CheckResult represents the result of an executed auxiliary health check.
+type CheckResult struct {
+	Name       string `json:"name,omitempty"`
+	Type       string `json:"type"`
+	Target     string `json:"target"`
+	Severity   string `json:"severity"`
+	Group      string `json:"group,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Duration   string `json:"duration"`
+	Age        string `json:"age,omitempty"`
+	Passed     bool   `json:"passed"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckGroupResult represents the result of a check group along with its members.
+type CheckGroupResult struct {
+	Name     string   `json:"name"`
+	Severity string   `json:"severity"`
+	Required int      `json:"required"`
+	Passing  int      `json:"passing"`
+	Total    int      `json:"total"`
+	Passed   bool     `json:"passed"`
+	Members  []string `json:"members"`
+	Failed   []string `json:"failed,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// SkippedCheck represents an auxiliary health check that wasn't executed for the request.
+type SkippedCheck struct {
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+// ResponseHealthJSON represents response body in the application/health+json format.
+type ResponseHealthJSON struct {
+	Status    string                       `json:"status"`
+	Version   string                       `json:"version,omitempty"`
+	ServiceID string                       `json:"serviceId,omitempty"`
+	Output    string                       `json:"output,omitempty"`
+	Checks    map[string][]HealthJSONCheck `json:"checks,omitempty"`
+}
+
+// HealthJSONCheck represents a single check in the application/health+json format.
+type HealthJSONCheck struct {
+	ComponentID   string      `json:"componentId,omitempty"`
+	ComponentType string      `json:"componentType,omitempty"`
+	ObservedValue interface{} `json:"observedValue,omitempty"`
+	ObservedUnit  string      `json:"observedUnit,omitempty"`
+	Status        string      `json:"status"`
+	Time          string      `json:"time,omitempty"`
+	Output        string      `json:"output,omitempty"`
 }
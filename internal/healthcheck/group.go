@@ -0,0 +1,122 @@
+package healthcheck
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/quorum"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// runGroups evaluates the quorum of every check group that has at least one executed
+// member. The error is the first group that failed.
+func (h *HealthCheck) runGroups(requestID string, report *Report) error {
+	members := map[string][]Result{}
+
+	for _, result := range report.Results {
+		if result.Group != "" {
+			members[result.Group] = append(members[result.Group], result)
+		}
+	}
+
+	groups := make([]string, 0, len(members))
+	for name := range members {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+
+	var firstErr error
+
+	for _, name := range groups {
+		group := evaluateGroup(h.checkGroup(name), members[name])
+
+		if group.Err != nil {
+			h.Logger.Error("check group",
+				zap.String("request_id", requestID),
+				zap.String("group", name),
+				zap.Strings("failed", group.Failed),
+				zap.Error(group.Err),
+			)
+		}
+
+		report.group(group)
+
+		if err := group.failure(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// checkGroup returns the definition of the group. A group that isn't defined
+// requires all its members to pass.
+func (h *HealthCheck) checkGroup(name string) config.CheckGroup {
+	for _, group := range h.Config.CheckGroups {
+		if group.Name == name {
+			return group
+		}
+	}
+
+	return config.CheckGroup{Name: name}
+}
+
+func evaluateGroup(group config.CheckGroup, members []Result) GroupResult {
+	result := GroupResult{
+		Name:     group.Name,
+		Severity: severity(group.Severity),
+		Total:    len(members),
+	}
+
+	for _, member := range members {
+		result.Members = append(result.Members, member.id())
+
+		if member.Passed {
+			result.Passing++
+		} else {
+			result.Failed = append(result.Failed, member.id())
+		}
+	}
+
+	sort.Strings(result.Members)
+	sort.Strings(result.Failed)
+
+	required, err := minPassing(group.MinPassing, result.Total)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Required = required
+
+	if result.Passing < required {
+		result.Err = xerrors.Errorf("check group %s failed, %d of %d checks passed, required: %d",
+			group.Name, result.Passing, result.Total, required)
+		return result
+	}
+
+	result.Passed = true
+
+	return result
+}
+
+// minPassing returns how many of total checks have to pass. The value is either
+// a number of checks or a percentage, e.g. 2 or 50%. All checks have to pass by default.
+func minPassing(value string, total int) (int, error) {
+	if strings.TrimSpace(value) == "" {
+		return total, nil
+	}
+
+	v, err := quorum.Required(value, total)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid minimum of passing checks, minPassing: %s: %w", value, err)
+	}
+
+	// Not all members have to be executed for a given request.
+	if v > total {
+		return total, nil
+	}
+
+	return v, nil
+}
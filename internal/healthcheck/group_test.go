@@ -0,0 +1,67 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"golang.org/x/xerrors"
+)
+
+func TestMinPassing(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		value    string
+		total    int
+		expected int
+		err      bool
+	}{
+		{value: "", total: 3, expected: 3},
+		{value: "2", total: 3, expected: 2},
+		{value: "5", total: 3, expected: 3},
+		{value: "50%", total: 3, expected: 2},
+		{value: "100%", total: 3, expected: 3},
+		{value: "0%", total: 3, expected: 0},
+		{value: "150%", total: 3, err: true},
+		{value: "-1", total: 3, err: true},
+		{value: "two", total: 3, err: true},
+	}
+
+	for _, item := range table {
+		required, err := minPassing(item.value, item.total)
+		if item.err {
+			assert.Error(t, err, item.value)
+			continue
+		}
+
+		assert.NoError(t, err, item.value)
+		assert.Equal(t, item.expected, required, item.value)
+	}
+}
+
+func TestEvaluateGroup(t *testing.T) {
+	t.Parallel()
+
+	members := []Result{
+		{Name: "mirror-b", Passed: true},
+		{Name: "mirror-a", Passed: true},
+		Result{Target: "mirror-c:80"}.fail(xerrors.New("connection refused")),
+	}
+
+	group := evaluateGroup(config.CheckGroup{Name: "mirrors", MinPassing: "2"}, members)
+	assert.True(t, group.Passed)
+	assert.NoError(t, group.failure())
+	assert.Equal(t, 2, group.Passing)
+	assert.Equal(t, 3, group.Total)
+	assert.Equal(t, []string{"mirror-a", "mirror-b", "mirror-c:80"}, group.Members)
+	assert.Equal(t, []string{"mirror-c:80"}, group.Failed)
+
+	group = evaluateGroup(config.CheckGroup{Name: "mirrors"}, members)
+	assert.False(t, group.Passed)
+	assert.EqualError(t, group.failure(), "check group mirrors failed, 2 of 3 checks passed, required: 3")
+
+	group = evaluateGroup(config.CheckGroup{Name: "mirrors", Severity: SeverityWarning}, members)
+	assert.False(t, group.Passed)
+	assert.NoError(t, group.failure())
+}
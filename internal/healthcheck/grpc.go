@@ -0,0 +1,129 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/version"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultGRPCTimeout limits checks without a timeout, so a server that never answers
+// doesn't block the check.
+const defaultGRPCTimeout = 5 * time.Second
+
+func (h *HealthCheck) runGRPCHealthCheck(c *fiber.Ctx, requestID string, report *Report) error {
+	g := new(errgroup.Group)
+	g.SetLimit(h.Config.Concurrency)
+
+	for i, check := range h.Config.GRPCHealthCheck {
+		// Skip a given health check if service or namespace doesn't match.
+		if reason := h.skipReason(c, requestID, check); reason != "" {
+			report.skip(Skipped{Name: check.Name, Type: GRPC, Target: check.Target, Reason: reason})
+			continue
+		}
+
+		healthCheck := check // https://golang.org/doc/faq#closures_and_goroutines
+
+		key := fmt.Sprintf("%s/%d", GRPC, i)
+
+		g.Go(func() error {
+			sched := schedule{interval: healthCheck.Interval, rise: healthCheck.Rise, fall: healthCheck.Fall}
+			result := h.check(requestID, key, sched, func(requestID string) Result {
+				return h.execGRPCHealthCheck(requestID, healthCheck)
+			})
+			report.add(result)
+			return result.failure()
+		})
+	}
+	return g.Wait()
+}
+
+func (h *HealthCheck) execGRPCHealthCheck(requestID string, check config.GRPCHealthCheck) Result {
+	result := Result{
+		Name:     check.Name,
+		Type:     GRPC,
+		Target:   check.Target,
+		Severity: severity(check.Severity),
+		Group:    check.Group,
+	}
+
+	start := time.Now()
+	status, err := checkGRPC(context.Background(), check)
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		h.Logger.Error("external health check",
+			zap.String("request_id", requestID),
+			zap.String("target", check.Target),
+			zap.String("grpc_service", check.GRPCService),
+			zap.Error(err),
+		)
+		return result.fail(err)
+	}
+
+	h.Logger.Info("external health check",
+		zap.String("request_id", requestID),
+		zap.String("target", check.Target),
+		zap.String("grpc_service", check.GRPCService),
+		zap.String("status", status.String()),
+	)
+
+	if status != healthpb.HealthCheckResponse_SERVING {
+		return result.fail(xerrors.Errorf("health check failed, status: %s, target: %s, service: %q", status, check.Target, check.GRPCService))
+	}
+
+	result.Passed = true
+
+	return result
+}
+
+// checkGRPC calls the grpc.health.v1.Health/Check method of the target and returns the reported serving status.
+func checkGRPC(ctx context.Context, check config.GRPCHealthCheck) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	creds := insecure.NewCredentials()
+	if check.TLS {
+		creds = credentials.NewTLS(&tls.Config{
+			InsecureSkipVerify: check.InsecureSkipVerify, //nolint:gosec
+		})
+	}
+
+	conn, err := grpc.DialContext(ctx, check.Target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent(fmt.Sprintf("healthgroup/%s", version.Version)),
+	)
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, grpcTimeout(check))
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: check.GRPCService,
+	})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, xerrors.Errorf("health check failed, target: %s: %w", check.Target, err)
+	}
+
+	return resp.GetStatus(), nil
+}
+
+// grpcTimeout returns the time limit of the health check request.
+func grpcTimeout(check config.GRPCHealthCheck) time.Duration {
+	if check.Timeout == 0 {
+		return defaultGRPCTimeout
+	}
+
+	return check.Timeout
+}
@@ -0,0 +1,114 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestCheckGRPC(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("serving", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("not-serving", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	t.Cleanup(server.Stop)
+
+	table := []struct {
+		desc     string
+		service  string
+		expected healthpb.HealthCheckResponse_ServingStatus
+		err      bool
+	}{
+		{
+			desc:     "server",
+			service:  "",
+			expected: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			desc:     "serving service",
+			service:  "serving",
+			expected: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			desc:     "not serving service",
+			service:  "not-serving",
+			expected: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			desc:     "unknown service",
+			service:  "unknown",
+			expected: healthpb.HealthCheckResponse_UNKNOWN,
+			err:      true,
+		},
+	}
+
+	for _, item := range table {
+		t.Run(item.desc, func(t *testing.T) {
+			status, err := checkGRPC(context.Background(), config.GRPCHealthCheck{
+				Target:      ln.Addr().String(),
+				GRPCService: item.service,
+				Timeout:     time.Second,
+			})
+			assert.Equal(t, item.expected, status)
+			assert.Equal(t, item.err, err != nil, err)
+		})
+	}
+}
+
+// silentHealthServer accepts health checks but never answers them.
+type silentHealthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (silentHealthServer) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCheckGRPCTimeout(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, silentHealthServer{})
+
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	t.Cleanup(server.Stop)
+
+	// The check is limited by the default timeout if it doesn't define one.
+	start := time.Now()
+	status, err := checkGRPC(context.Background(), config.GRPCHealthCheck{
+		Target: ln.Addr().String(),
+	})
+	assert.Error(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_UNKNOWN, status)
+	assert.WithinDuration(t, start.Add(defaultGRPCTimeout), time.Now(), time.Second)
+
+	assert.Equal(t, time.Second, grpcTimeout(config.GRPCHealthCheck{Timeout: time.Second}))
+}
@@ -10,6 +10,9 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/hysteresis"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
+	"github.com/tczekajlo/healthgroup/internal/scheduler"
 	"github.com/tczekajlo/healthgroup/internal/version"
 	"go.uber.org/zap"
 	"golang.org/x/net/http2"
@@ -20,86 +23,223 @@ import (
 const (
 	HTTP2 string = "http2"
 	HTTP  string = "http"
+	TCP   string = "tcp"
+	GRPC  string = "grpc"
+)
+
+const (
+	// SeverityCritical checks fail the whole group.
+	SeverityCritical string = "critical"
+	// SeverityWarning checks only degrade the group.
+	SeverityWarning string = "warning"
 )
 
 type HealthCheck struct {
 	Logger    *zap.Logger
 	Config    *config.Config
+	Metrics   *metrics.Metrics
+	Scheduler *scheduler.Scheduler
+	Tracker   *hysteresis.Tracker
 	Discovery string
 }
 
-func (h *HealthCheck) Run(c *fiber.Ctx) error {
+// schedule defines how often a health check is evaluated and how many consecutive
+// outcomes are needed to change its state.
+type schedule struct {
+	interval time.Duration
+	rise     int
+	fall     int
+}
+
+// Run executes all auxiliary health checks that match the request. The returned
+// report contains the results of all executed and skipped checks and check groups,
+// the error is the first failure.
+func (h *HealthCheck) Run(c *fiber.Ctx) (*Report, error) {
+	report := &Report{}
 	g := new(errgroup.Group)
 
+	// The context isn't safe for concurrent use, read the request ID only once.
+	requestID := c.GetRespHeader("X-Request-Id")
+
 	g.Go(func() error {
-		return h.runHTTPHealthCheck(c)
+		return h.runHTTPHealthCheck(c, requestID, report)
 	})
 
-	// Wait for all HTTP fetches to complete.
-	return g.Wait()
+	g.Go(func() error {
+		return h.runTCPHealthCheck(c, requestID, report)
+	})
+
+	g.Go(func() error {
+		return h.runGRPCHealthCheck(c, requestID, report)
+	})
+
+	// Wait for all health checks to complete.
+	err := g.Wait()
+
+	// Check groups are evaluated even if any other check failed to be included in the report.
+	if groupErr := h.runGroups(requestID, report); err == nil {
+		err = groupErr
+	}
+
+	return report, err
 }
 
-func (h *HealthCheck) runHTTPHealthCheck(c *fiber.Ctx) error {
+func (h *HealthCheck) runHTTPHealthCheck(c *fiber.Ctx, requestID string, report *Report) error {
 	g := new(errgroup.Group)
 	g.SetLimit(h.Config.Concurrency)
 
-	for _, check := range h.Config.HTTPHealthCheck {
+	for i, check := range h.Config.HTTPHealthCheck {
 		// Skip a given health check if service or namespace doesn't match.
-		if h.shouldSkip(c, check) {
+		if reason := h.skipReason(c, requestID, check); reason != "" {
+			url, _ := buildURL(check)
+			report.skip(Skipped{Name: check.Name, Type: strings.ToLower(check.Type), Target: url, Reason: reason})
 			continue
 		}
 
 		healthCheck := check // https://golang.org/doc/faq#closures_and_goroutines
 
+		key := fmt.Sprintf("%s/%d", HTTP, i)
+
 		g.Go(func() error {
-			return h.execHTTPHealthCheck(c, healthCheck)
+			sched := schedule{interval: healthCheck.Interval, rise: healthCheck.Rise, fall: healthCheck.Fall}
+			result := h.check(requestID, key, sched, func(requestID string) Result {
+				return h.execHTTPHealthCheck(requestID, healthCheck)
+			})
+			report.add(result)
+			return result.failure()
 		})
 	}
 	return g.Wait()
 }
 
+// check executes the health check. If the scheduler is enabled, the latest result
+// of the check evaluated in the background is returned instead.
+func (h *HealthCheck) check(requestID, key string, sched schedule, exec func(requestID string) Result) Result {
+	run := func(requestID string) Result {
+		result := exec(requestID)
+		result.Time = time.Now()
+
+		h.Metrics.ObserveCheck(result.Type, result.id(), result.Target, result.Passed, result.Duration)
+
+		return h.observe(requestID, key, sched, result)
+	}
+
+	if h.Scheduler == nil {
+		return run(requestID)
+	}
+
+	interval := sched.interval
+	if interval == 0 {
+		interval = h.Config.Scheduler.Interval
+	}
+
+	value, updated := h.Scheduler.Get("check/"+key, interval, func() interface{} {
+		return run("")
+	})
+
+	result, _ := value.(Result)
+	result.Age = time.Since(updated)
+
+	return result
+}
+
+// observe applies the rise and fall thresholds to the outcome of the health check.
+// A failed check that is still considered as passing keeps its error.
+func (h *HealthCheck) observe(requestID, key string, sched schedule, result Result) Result {
+	passed := h.Tracker.Observe("check/"+key, result.Passed, sched.rise, sched.fall)
+	if passed == result.Passed {
+		return result
+	}
+
+	h.Logger.Info("health check state unchanged",
+		zap.String("request_id", requestID),
+		zap.String("target", result.Target),
+		zap.Bool("passed", result.Passed),
+		zap.Bool("state", passed),
+	)
+
+	if passed {
+		result.Passed = true
+		return result
+	}
+
+	return result.fail(xerrors.Errorf("health check hasn't passed %d times in a row yet, target: %s", sched.rise, result.Target))
+}
+
 func (h *HealthCheck) shouldSkip(c *fiber.Ctx, check interface{}) bool {
-	var checkNS, checkSVC, checkDiscovery string
+	return h.skipReason(c, c.GetRespHeader("X-Request-Id"), check) != ""
+}
+
+// skipReason returns the reason why a given health check shouldn't be executed for
+// the request, or an empty string if it should be executed.
+func (h *HealthCheck) skipReason(c *fiber.Ctx, requestID string, check interface{}) string {
+	var checkNS, checkSVC, checkDiscovery, checkCluster string
 	namespace := c.Params("namespace")
-	service := c.Params("service")
-	requestID := c.GetRespHeader("X-Request-Id")
+	service := Service(c)
+	cluster := c.Params("cluster")
 
-	switch v := check.(type) { //nolint:gocritic
+	switch v := check.(type) {
 	case config.HTTPHealthCheck:
 		checkNS = v.Namespace
 		checkSVC = v.Service
 		checkDiscovery = strings.ToLower(v.Discovery)
+		checkCluster = v.Cluster
+	case config.TCPHealthCheck:
+		checkNS = v.Namespace
+		checkSVC = v.Service
+		checkDiscovery = strings.ToLower(v.Discovery)
+		checkCluster = v.Cluster
+	case config.GRPCHealthCheck:
+		checkNS = v.Namespace
+		checkSVC = v.Service
+		checkDiscovery = strings.ToLower(v.Discovery)
+		checkCluster = v.Cluster
 	}
 
-	if h.Discovery != checkDiscovery && checkDiscovery != "" {
-		h.Logger.Debug("skip health check",
-			zap.String("request_id", requestID),
-			zap.Any("health_check", check),
-		)
-		return true
+	var reason string
+
+	switch {
+	case h.Discovery != checkDiscovery && checkDiscovery != "":
+		reason = fmt.Sprintf("discovery doesn't match: %s", checkDiscovery)
+	case checkCluster != cluster && checkCluster != "":
+		reason = fmt.Sprintf("cluster doesn't match: %s", checkCluster)
+	case checkNS != namespace && checkNS != "":
+		reason = fmt.Sprintf("namespace doesn't match: %s", checkNS)
+	case checkSVC != service && checkSVC != "":
+		reason = fmt.Sprintf("service doesn't match: %s", checkSVC)
+	default:
+		return ""
 	}
 
-	if checkNS != namespace && checkNS != "" {
-		h.Logger.Debug("skip health check",
-			zap.String("request_id", requestID),
-			zap.Any("health_check", check),
-		)
-		return true
-	}
+	h.Logger.Debug("skip health check",
+		zap.String("request_id", requestID),
+		zap.String("reason", reason),
+		zap.Any("health_check", check),
+	)
 
-	if checkSVC != service && checkSVC != "" {
-		h.Logger.Debug("skip health check",
-			zap.String("request_id", requestID),
-			zap.Any("health_check", check),
-		)
-		return true
+	return reason
+}
+
+// Service returns the name of the service of the request. The name of a prepared
+// query or a workload, or the label selector, is used if the service isn't defined.
+func Service(c *fiber.Ctx) string {
+	for _, param := range []string{"service", "query", "name"} {
+		if value := c.Params(param); value != "" {
+			return value
+		}
 	}
 
-	return false
+	return c.Query("selector")
 }
 
-func (h *HealthCheck) execHTTPHealthCheck(c *fiber.Ctx, check config.HTTPHealthCheck) error {
-	requestID := c.GetRespHeader("X-Request-Id")
+func (h *HealthCheck) execHTTPHealthCheck(requestID string, check config.HTTPHealthCheck) Result {
+	result := Result{
+		Name:     check.Name,
+		Type:     strings.ToLower(check.Type),
+		Severity: severity(check.Severity),
+		Group:    check.Group,
+	}
+
 	url, err := buildURL(check)
 	if err != nil {
 		h.Logger.Error("external health check",
@@ -107,8 +247,9 @@ func (h *HealthCheck) execHTTPHealthCheck(c *fiber.Ctx, check config.HTTPHealthC
 			zap.Any("health_check", check),
 			zap.Error(err),
 		)
-		return err
+		return result.fail(err)
 	}
+	result.Target = url
 
 	client := http.Client{
 		Timeout: check.Timeout * time.Second,
@@ -131,11 +272,14 @@ func (h *HealthCheck) execHTTPHealthCheck(c *fiber.Ctx, check config.HTTPHealthC
 
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return result.fail(err)
 	}
 	req.Header.Set("User-Agent", fmt.Sprintf("healthgroup/%s", version.Version))
 
+	start := time.Now()
 	resp, err := client.Do(req)
+	result.Duration = time.Since(start)
+
 	if err == nil {
 		resp.Body.Close()
 		client.CloseIdleConnections()
@@ -146,20 +290,32 @@ func (h *HealthCheck) execHTTPHealthCheck(c *fiber.Ctx, check config.HTTPHealthC
 			zap.Int("status", resp.StatusCode),
 		)
 
+		result.StatusCode = resp.StatusCode
 		if resp.StatusCode != http.StatusOK {
-			return xerrors.Errorf("health check failed, status code: %d, url: %s", resp.StatusCode, url)
+			return result.fail(xerrors.Errorf("health check failed, status code: %d, url: %s", resp.StatusCode, url))
 		}
 	} else {
-		resp.Body.Close()
 		client.CloseIdleConnections()
 		h.Logger.Error("external health check",
 			zap.String("request_id", requestID),
 			zap.String("url", url),
 			zap.Error(err),
 		)
+		return result.fail(err)
+	}
+
+	result.Passed = true
+
+	return result
+}
+
+// severity returns the normalized severity of a check, checks are critical by default.
+func severity(s string) string {
+	if strings.ToLower(s) == SeverityWarning {
+		return SeverityWarning
 	}
 
-	return err
+	return SeverityCritical
 }
 
 func buildURL(healthCheck config.HTTPHealthCheck) (string, error) {
@@ -10,7 +10,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/tczekajlo/healthgroup/internal/config"
 	"github.com/tczekajlo/healthgroup/internal/discovery"
+	"github.com/tczekajlo/healthgroup/internal/hysteresis"
 	"github.com/tczekajlo/healthgroup/internal/log"
+	"golang.org/x/xerrors"
 )
 
 func TestBuildURL(t *testing.T) {
@@ -111,7 +113,7 @@ func TestShouldSkip(t *testing.T) {
 
 	table := []struct {
 		desc        string
-		healthCheck config.HTTPHealthCheck
+		healthCheck interface{}
 		expected    bool
 		path        string
 		route       string
@@ -216,6 +218,95 @@ func TestShouldSkip(t *testing.T) {
 			path:     "/health/kubernetes/ns/testservice2",
 			route:    "/health/kubernetes/:namespace/:service",
 		},
+		{
+			desc: "consul-query - match query",
+			healthCheck: config.HTTPHealthCheck{
+				Type:    "http",
+				Host:    "example.com",
+				Service: "redis-nearest",
+			},
+			expected: false,
+			path:     "/health/consul-query/redis-nearest",
+			route:    "/health/consul-query/:query",
+		},
+		{
+			desc: "consul-query - skip query",
+			healthCheck: config.HTTPHealthCheck{
+				Type:    "http",
+				Host:    "example.com",
+				Service: "redis-nearest",
+			},
+			expected: true,
+			path:     "/health/consul-query/redis-failover",
+			route:    "/health/consul-query/:query",
+		},
+		{
+			desc: "kubernetes-selector - match selector",
+			healthCheck: config.HTTPHealthCheck{
+				Type:    "http",
+				Host:    "example.com",
+				Service: "app=api",
+			},
+			expected: false,
+			path:     "/health/kubernetes-selector/ns?selector=app=api",
+			route:    "/health/kubernetes-selector/:namespace",
+		},
+		{
+			desc: "kubernetes cluster - match cluster",
+			healthCheck: config.HTTPHealthCheck{
+				Type:    "http",
+				Host:    "example.com",
+				Cluster: "eu-west",
+			},
+			expected: false,
+			path:     "/health/kubernetes-cluster/eu-west/ns/testservice",
+			route:    "/health/kubernetes-cluster/:cluster/:namespace/:service",
+		},
+		{
+			desc: "kubernetes cluster - skip cluster",
+			healthCheck: config.HTTPHealthCheck{
+				Type:    "http",
+				Host:    "example.com",
+				Cluster: "eu-west",
+			},
+			expected: true,
+			path:     "/health/kubernetes-cluster/us-east/ns/testservice",
+			route:    "/health/kubernetes-cluster/:cluster/:namespace/:service",
+		},
+		{
+			desc: "kubernetes - skip cluster",
+			healthCheck: config.TCPHealthCheck{
+				Host:    "example.com",
+				Port:    5432,
+				Cluster: "eu-west",
+			},
+			expected: true,
+			path:     "/health/kubernetes/ns/testservice",
+			route:    "/health/kubernetes/:namespace/:service",
+		},
+		{
+			desc: "tcp - skip service",
+			healthCheck: config.TCPHealthCheck{
+				Host:    "example.com",
+				Port:    5432,
+				Service: "testservice",
+			},
+			expected: true,
+			path:     "/health/kubernetes/ns/testservice2",
+			route:    "/health/kubernetes/:namespace/:service",
+		},
+		{
+			desc: "tcp - match namespace and discovery",
+			healthCheck: config.TCPHealthCheck{
+				Host:      "example.com",
+				Port:      5432,
+				Namespace: "ns",
+				Discovery: discovery.Kubernetes,
+			},
+			expected: false,
+			path:     "/health/kubernetes/ns/testservice2",
+			route:    "/health/kubernetes/:namespace/:service",
+		},
 	}
 
 	for _, item := range table {
@@ -233,7 +324,44 @@ func TestShouldSkip(t *testing.T) {
 	}
 }
 
-func httpTestHandler(t *testing.T, check HealthCheck, health config.HTTPHealthCheck, expected bool) fiber.Handler {
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	logger, _ := log.NewAtLevel("ERROR")
+
+	h := HealthCheck{
+		Logger:  logger,
+		Tracker: hysteresis.New(),
+	}
+	sched := schedule{rise: 2, fall: 2}
+
+	passed := Result{Target: "redis:6379", Passed: true}
+	failed := Result{Target: "redis:6379"}.fail(xerrors.New("connection refused"))
+
+	result := h.observe("", "tcp/0", sched, passed)
+	assert.True(t, result.Passed)
+	assert.Empty(t, result.failure())
+
+	// A single failure doesn't change the state, the error is kept.
+	result = h.observe("", "tcp/0", sched, failed)
+	assert.True(t, result.Passed)
+	assert.Empty(t, result.failure())
+	assert.Error(t, result.Err)
+
+	result = h.observe("", "tcp/0", sched, failed)
+	assert.False(t, result.Passed)
+	assert.Error(t, result.failure())
+
+	// A single success doesn't change the state either.
+	result = h.observe("", "tcp/0", sched, passed)
+	assert.False(t, result.Passed)
+	assert.EqualError(t, result.failure(), "health check hasn't passed 2 times in a row yet, target: redis:6379")
+
+	result = h.observe("", "tcp/0", sched, passed)
+	assert.True(t, result.Passed)
+}
+
+func httpTestHandler(t *testing.T, check HealthCheck, health interface{}, expected bool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ex := check.shouldSkip(c, health)
 		assert.Equal(t, expected, ex)
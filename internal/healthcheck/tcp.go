@@ -0,0 +1,157 @@
+package healthcheck
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+// maxTCPResponseSize limits how many bytes are read while waiting for the expected response.
+const maxTCPResponseSize = 4096
+
+// defaultTCPTimeout limits checks without a timeout, so a peer that never answers
+// doesn't block the check.
+const defaultTCPTimeout = 5 * time.Second
+
+func (h *HealthCheck) runTCPHealthCheck(c *fiber.Ctx, requestID string, report *Report) error {
+	g := new(errgroup.Group)
+	g.SetLimit(h.Config.Concurrency)
+
+	for i, check := range h.Config.TCPHealthCheck {
+		// Skip a given health check if service or namespace doesn't match.
+		if reason := h.skipReason(c, requestID, check); reason != "" {
+			report.skip(Skipped{Name: check.Name, Type: TCP, Target: tcpAddr(check), Reason: reason})
+			continue
+		}
+
+		healthCheck := check // https://golang.org/doc/faq#closures_and_goroutines
+
+		key := fmt.Sprintf("%s/%d", TCP, i)
+
+		g.Go(func() error {
+			sched := schedule{interval: healthCheck.Interval, rise: healthCheck.Rise, fall: healthCheck.Fall}
+			result := h.check(requestID, key, sched, func(requestID string) Result {
+				return h.execTCPHealthCheck(requestID, healthCheck)
+			})
+			report.add(result)
+			return result.failure()
+		})
+	}
+	return g.Wait()
+}
+
+func (h *HealthCheck) execTCPHealthCheck(requestID string, check config.TCPHealthCheck) Result {
+	addr := tcpAddr(check)
+	result := Result{
+		Name:     check.Name,
+		Type:     TCP,
+		Target:   addr,
+		Severity: severity(check.Severity),
+		Group:    check.Group,
+	}
+
+	start := time.Now()
+	err := dialTCP(addr, check)
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		h.Logger.Error("external health check",
+			zap.String("request_id", requestID),
+			zap.String("addr", addr),
+			zap.Error(err),
+		)
+		return result.fail(err)
+	}
+
+	h.Logger.Info("external health check",
+		zap.String("request_id", requestID),
+		zap.String("addr", addr),
+	)
+
+	result.Passed = true
+
+	return result
+}
+
+func tcpAddr(check config.TCPHealthCheck) string {
+	return net.JoinHostPort(check.Host, strconv.Itoa(check.Port))
+}
+
+// dialTCP opens a TCP connection to the given address, optionally sends
+// the configured payload and waits for a response matching the expected regex.
+func dialTCP(addr string, check config.TCPHealthCheck) error {
+	var expect *regexp.Regexp
+
+	if check.Expect != "" {
+		re, err := regexp.Compile(check.Expect)
+		if err != nil {
+			return xerrors.Errorf("invalid expected response, expect: %s: %w", check.Expect, err)
+		}
+		expect = re
+	}
+
+	timeout := tcpTimeout(check)
+
+	dialer := net.Dialer{
+		Timeout: timeout,
+	}
+
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return xerrors.Errorf("health check failed, addr: %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	if check.Send != "" {
+		if _, err := conn.Write([]byte(check.Send)); err != nil {
+			return xerrors.Errorf("health check failed, unable to send payload, addr: %s: %w", addr, err)
+		}
+	}
+
+	if expect == nil {
+		return nil
+	}
+
+	buf := make([]byte, maxTCPResponseSize)
+	size := 0
+
+	for size < len(buf) {
+		n, err := conn.Read(buf[size:])
+		size += n
+
+		if expect.Match(buf[:size]) {
+			return nil
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return xerrors.Errorf("health check failed, unable to read response, addr: %s: %w", addr, err)
+		}
+	}
+
+	return xerrors.Errorf("health check failed, response doesn't match %q, addr: %s", check.Expect, addr)
+}
+
+// tcpTimeout returns the time limit of the whole check.
+func tcpTimeout(check config.TCPHealthCheck) time.Duration {
+	if check.Timeout == 0 {
+		return defaultTCPTimeout
+	}
+
+	return check.Timeout
+}
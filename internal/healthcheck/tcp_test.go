@@ -0,0 +1,134 @@
+package healthcheck
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+)
+
+func newTCPEchoServer(t *testing.T) (string, int) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				_, _ = conn.Write([]byte("+OK ready\r\n"))
+
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte(line))
+			}(conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	return host, p
+}
+
+func TestDialTCP(t *testing.T) {
+	t.Parallel()
+
+	host, port := newTCPEchoServer(t)
+
+	table := []struct {
+		desc    string
+		check   config.TCPHealthCheck
+		success bool
+	}{
+		{
+			desc: "connect only",
+			check: config.TCPHealthCheck{
+				Host:    host,
+				Port:    port,
+				Timeout: time.Second,
+			},
+			success: true,
+		},
+		{
+			desc: "expect banner",
+			check: config.TCPHealthCheck{
+				Host:    host,
+				Port:    port,
+				Timeout: time.Second,
+				Expect:  `^\+OK`,
+			},
+			success: true,
+		},
+		{
+			desc: "send and expect echo",
+			check: config.TCPHealthCheck{
+				Host:    host,
+				Port:    port,
+				Timeout: time.Second,
+				Send:    "PING\n",
+				Expect:  "PING",
+			},
+			success: true,
+		},
+		{
+			desc: "unexpected response",
+			check: config.TCPHealthCheck{
+				Host:    host,
+				Port:    port,
+				Timeout: 200 * time.Millisecond,
+				Expect:  "PONG",
+			},
+			success: false,
+		},
+		{
+			desc: "invalid regex",
+			check: config.TCPHealthCheck{
+				Host:    host,
+				Port:    port,
+				Timeout: time.Second,
+				Expect:  "(",
+			},
+			success: false,
+		},
+		{
+			desc: "connection refused",
+			check: config.TCPHealthCheck{
+				Host:    host,
+				Port:    1,
+				Timeout: time.Second,
+			},
+			success: false,
+		},
+	}
+
+	for _, item := range table {
+		t.Run(item.desc, func(t *testing.T) {
+			addr := net.JoinHostPort(item.check.Host, strconv.Itoa(item.check.Port))
+			err := dialTCP(addr, item.check)
+			assert.Equal(t, item.success, err == nil, err)
+		})
+	}
+}
+
+func TestTCPTimeout(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, defaultTCPTimeout, tcpTimeout(config.TCPHealthCheck{}))
+	assert.Equal(t, time.Second, tcpTimeout(config.TCPHealthCheck{Timeout: time.Second}))
+}
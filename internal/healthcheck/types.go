@@ -0,0 +1,128 @@
+package healthcheck
+
+import (
+	"sync"
+	"time"
+)
+
+// Result represents the outcome of an executed health check. Err may be set even
+// if the check passed, when a failure hasn't reached the fall threshold yet. Time is
+// when the check was executed.
+type Result struct {
+	Name       string
+	Type       string
+	Target     string
+	Severity   string
+	Group      string
+	StatusCode int
+	Duration   time.Duration
+	Time       time.Time
+	Age        time.Duration
+	Passed     bool
+	Err        error
+}
+
+// Skipped represents a health check that hasn't been executed for a given request.
+type Skipped struct {
+	Name   string
+	Type   string
+	Target string
+	Reason string
+}
+
+// GroupResult represents the outcome of a check group, it passes if at least the
+// required number of its members passed.
+type GroupResult struct {
+	Name     string
+	Severity string
+	Required int
+	Passing  int
+	Total    int
+	Members  []string
+	Failed   []string
+	Passed   bool
+	Err      error
+}
+
+// Report collects results of all health checks executed for a single request.
+type Report struct {
+	mu      sync.Mutex
+	Results []Result
+	Skipped []Skipped
+	Groups  []GroupResult
+}
+
+func (r *Report) add(result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Results = append(r.Results, result)
+}
+
+// Degraded returns true if any non-critical check failed.
+func (r *Report) Degraded() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, result := range r.Results {
+		if !result.Passed && result.Severity == SeverityWarning && result.Group == "" {
+			return true
+		}
+	}
+
+	for _, group := range r.Groups {
+		if !group.Passed && group.Severity == SeverityWarning {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Report) group(group GroupResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Groups = append(r.Groups, group)
+}
+
+func (r *Report) skip(skipped Skipped) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Skipped = append(r.Skipped, skipped)
+}
+
+func (r Result) fail(err error) Result {
+	r.Passed = false
+	r.Err = err
+
+	return r
+}
+
+// failure returns the error of the check if it fails the whole group. Failed
+// non-critical checks don't, members of check groups are evaluated by the group.
+func (r Result) failure() error {
+	if r.Passed || r.Severity == SeverityWarning || r.Group != "" {
+		return nil
+	}
+
+	return r.Err
+}
+
+// id returns the name of the check, or the target if the name isn't defined.
+func (r Result) id() string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	return r.Target
+}
+
+func (g GroupResult) failure() error {
+	if g.Passed || g.Severity == SeverityWarning {
+		return nil
+	}
+
+	return g.Err
+}
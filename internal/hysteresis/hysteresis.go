@@ -0,0 +1,58 @@
+package hysteresis
+
+import "sync"
+
+// Tracker keeps the state of health checks across requests. The state changes only
+// after the required number of consecutive successes (rise) or failures (fall).
+type Tracker struct {
+	mu     sync.Mutex
+	states map[string]*state
+}
+
+type state struct {
+	passed bool
+	count  int
+}
+
+func New() *Tracker {
+	return &Tracker{
+		states: map[string]*state{},
+	}
+}
+
+// Observe records the outcome of the check identified by key and returns its
+// current state. The first observation sets the state immediately. Thresholds
+// lower than 1 are treated as 1, which means that the state follows every outcome.
+// A nil tracker returns the outcome as it is.
+func (t *Tracker) Observe(key string, passed bool, rise, fall int) bool {
+	if t == nil {
+		return passed
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.states[key]
+	if !ok {
+		t.states[key] = &state{passed: passed}
+		return passed
+	}
+
+	if s.passed == passed {
+		s.count = 0
+		return s.passed
+	}
+
+	threshold := fall
+	if passed {
+		threshold = rise
+	}
+
+	s.count++
+	if s.count >= threshold {
+		s.passed = passed
+		s.count = 0
+	}
+
+	return s.passed
+}
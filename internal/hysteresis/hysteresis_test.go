@@ -0,0 +1,60 @@
+package hysteresis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rise     int
+		fall     int
+		outcomes []bool
+		expected []bool
+	}{
+		{
+			name:     "no thresholds",
+			outcomes: []bool{true, false, true, false},
+			expected: []bool{true, false, true, false},
+		},
+		{
+			name:     "fall",
+			rise:     1,
+			fall:     3,
+			outcomes: []bool{true, false, false, true, false, false, false, true},
+			expected: []bool{true, true, true, true, true, true, false, true},
+		},
+		{
+			name:     "rise",
+			rise:     2,
+			fall:     1,
+			outcomes: []bool{false, true, false, true, true, false},
+			expected: []bool{false, false, false, false, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := New()
+			for i, outcome := range tt.outcomes {
+				assert.Equal(t, tt.expected[i], tracker.Observe("check", outcome, tt.rise, tt.fall), "observation %d", i)
+			}
+		})
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	t.Parallel()
+
+	var tracker *Tracker
+
+	assert.False(t, tracker.Observe("check", false, 3, 3))
+	assert.True(t, tracker.Observe("check", true, 3, 3))
+}
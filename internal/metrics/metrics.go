@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "healthgroup"
+
+// Metrics holds Prometheus collectors of healthgroup. All methods are safe to call on a nil *Metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests          *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+	checks            *prometheus.CounterVec
+	checkDuration     *prometheus.HistogramVec
+	discoveryRequests *prometheus.HistogramVec
+	discoveryErrors   *prometheus.CounterVec
+}
+
+// New creates collectors and registers them in a new registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by route and status code.",
+		}, []string{"route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "status"}),
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "check_total",
+			Help:      "Total number of executed auxiliary health checks by result.",
+		}, []string{"type", "name", "target", "result"}),
+		checkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "check_duration_seconds",
+			Help:      "Duration of auxiliary health checks.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type", "name", "target"}),
+		discoveryRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "discovery_request_duration_seconds",
+			Help:      "Duration of requests made to the discovery service API.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"source", "operation"}),
+		discoveryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "discovery_errors_total",
+			Help:      "Total number of failed requests made to the discovery service API.",
+		}, []string{"source", "operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.checks,
+		m.checkDuration,
+		m.discoveryRequests,
+		m.discoveryErrors,
+	)
+
+	return m
+}
+
+// Handler returns a handler that exposes metrics in the Prometheus format.
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// ObserveRequest records an incoming HTTP request.
+func (m *Metrics) ObserveRequest(route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(route, code).Inc()
+	m.requestDuration.WithLabelValues(route, code).Observe(duration.Seconds())
+}
+
+// ObserveCheck records an executed auxiliary health check.
+func (m *Metrics) ObserveCheck(checkType, name, target string, passed bool, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	result := "success"
+	if !passed {
+		result = "failure"
+	}
+
+	m.checks.WithLabelValues(checkType, name, target, result).Inc()
+	m.checkDuration.WithLabelValues(checkType, name, target).Observe(duration.Seconds())
+}
+
+// ObserveDiscovery records a request made to the discovery service API.
+func (m *Metrics) ObserveDiscovery(source, operation string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	m.discoveryRequests.WithLabelValues(source, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.discoveryErrors.WithLabelValues(source, operation).Inc()
+	}
+}
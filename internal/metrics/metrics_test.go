@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestNilMetrics(t *testing.T) {
+	t.Parallel()
+
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.ObserveRequest("/health/consul/:service", fiber.StatusOK, time.Second)
+		m.ObserveCheck("http", "google", "https://google.com", true, time.Second)
+		m.ObserveDiscovery("consul", "health_service", time.Now(), nil)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	m.ObserveRequest("/health/consul/:service", fiber.StatusServiceUnavailable, time.Second)
+	m.ObserveCheck("tcp", "redis", "redis:6379", false, time.Millisecond)
+	m.ObserveDiscovery("kubernetes", "get_service", time.Now(), xerrors.New("timeout"))
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/metrics", m.Handler())
+
+	resp, err := app.Test(httptest.NewRequest("GET", "http://localhost/metrics", nil))
+	assert.Empty(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, expected := range []string{
+		`healthgroup_http_requests_total{route="/health/consul/:service",status="503"} 1`,
+		`healthgroup_check_total{name="redis",result="failure",target="redis:6379",type="tcp"} 1`,
+		`healthgroup_check_duration_seconds_count{name="redis",target="redis:6379",type="tcp"} 1`,
+		`healthgroup_discovery_errors_total{operation="get_service",source="kubernetes"} 1`,
+		`healthgroup_discovery_request_duration_seconds_count{operation="get_service",source="kubernetes"} 1`,
+	} {
+		assert.Contains(t, string(body), expected)
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
+)
+
+// Config defines the config for middleware
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// Metrics defines collectors used to record requests
+	Metrics *metrics.Metrics
+}
+
+// New creates a new middleware handler
+func New(config Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if config.Next != nil && config.Next(c) {
+			return c.Next()
+		}
+
+		start := time.Now()
+
+		chainErr := c.Next()
+
+		status := c.Response().StatusCode()
+		if chainErr != nil {
+			status = fiber.StatusInternalServerError
+
+			var e *fiber.Error
+			if errors.As(chainErr, &e) {
+				status = e.Code
+			}
+		}
+
+		config.Metrics.ObserveRequest(c.Route().Path, status, time.Since(start))
+
+		return chainErr
+	}
+}
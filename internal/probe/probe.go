@@ -0,0 +1,98 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tczekajlo/healthgroup/internal/version"
+	"golang.org/x/xerrors"
+)
+
+const (
+	TCP   string = "tcp"
+	HTTP  string = "http"
+	HTTPS string = "https"
+)
+
+// Probe checks a single address of a discovered service, e.g. an address that has no
+// readiness reported by the discovery source.
+type Probe struct {
+	// Type is either tcp, http or https.
+	Type               string
+	RequestPath        string
+	Timeout            time.Duration
+	InsecureSkipVerify bool
+}
+
+// IsSupported returns true if the probe type is supported.
+func IsSupported(t string) bool {
+	switch strings.ToLower(t) {
+	case TCP, HTTP, HTTPS:
+		return true
+	}
+
+	return false
+}
+
+// Check returns an error if the address doesn't accept connections, or if it doesn't
+// respond with 200 in case of HTTP probes.
+func (p Probe) Check(addr string) error {
+	switch strings.ToLower(p.Type) {
+	case TCP:
+		return p.dial(addr)
+	case HTTP, HTTPS:
+		return p.get(addr)
+	}
+
+	return xerrors.Errorf("probe type is not supported, type: %s", p.Type)
+}
+
+func (p Probe) dial(addr string) error {
+	dialer := net.Dialer{
+		Timeout: p.Timeout,
+	}
+
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return xerrors.Errorf("probe failed, addr: %s: %w", addr, err)
+	}
+
+	return conn.Close()
+}
+
+func (p Probe) get(addr string) error {
+	url := fmt.Sprintf("%s://%s%s", strings.ToLower(p.Type), addr, p.RequestPath)
+
+	client := http.Client{
+		Timeout: p.Timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: p.InsecureSkipVerify, //nolint:gosec
+			},
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("healthgroup/%s", version.Version))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("probe failed, url: %s: %w", url, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("probe failed, status code: %d, url: %s", resp.StatusCode, url)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package probe
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	closed.Close()
+
+	table := []struct {
+		probe Probe
+		addr  string
+		err   bool
+	}{
+		{probe: Probe{Type: TCP}, addr: addr},
+		{probe: Probe{Type: TCP}, addr: closed.Addr().String(), err: true},
+		{probe: Probe{Type: HTTP, RequestPath: "/healthz"}, addr: addr},
+		{probe: Probe{Type: "HTTP", RequestPath: "/healthz"}, addr: addr},
+		{probe: Probe{Type: HTTP, RequestPath: "/"}, addr: addr, err: true},
+		{probe: Probe{Type: HTTP}, addr: closed.Addr().String(), err: true},
+		{probe: Probe{Type: "grpc"}, addr: addr, err: true},
+	}
+
+	for _, item := range table {
+		item.probe.Timeout = time.Second
+		err := item.probe.Check(item.addr)
+		if item.err {
+			assert.Error(t, err, item.probe.Type)
+			continue
+		}
+		assert.NoError(t, err, item.probe.Type)
+	}
+}
+
+func TestIsSupported(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsSupported("tcp"))
+	assert.True(t, IsSupported("HTTPS"))
+	assert.False(t, IsSupported("grpc"))
+	assert.False(t, IsSupported(""))
+}
@@ -0,0 +1,37 @@
+package quorum
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Required returns how many of total items are required by value, which is either
+// a number of items, e.g. 2, or a percentage of total, e.g. 50%. Percentages are
+// rounded up.
+func Required(value string, total int) (int, error) {
+	value = strings.TrimSpace(value)
+
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		v, err := strconv.ParseFloat(percent, 64)
+		if err != nil || v < 0 || v > 100 {
+			return 0, xerrors.Errorf("invalid percentage: %s", value)
+		}
+
+		return int(math.Ceil(float64(total) * v / 100)), nil //nolint:gomnd
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil || v < 0 {
+		return 0, xerrors.Errorf("invalid number: %s", value)
+	}
+
+	return v, nil
+}
+
+// IsPercentage returns true if value is a percentage.
+func IsPercentage(value string) bool {
+	return strings.HasSuffix(strings.TrimSpace(value), "%")
+}
@@ -0,0 +1,41 @@
+package quorum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequired(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		value    string
+		total    int
+		expected int
+		err      bool
+	}{
+		{value: "2", total: 3, expected: 2},
+		{value: "5", total: 3, expected: 5},
+		{value: " 1 ", total: 3, expected: 1},
+		{value: "50%", total: 3, expected: 2},
+		{value: "100%", total: 3, expected: 3},
+		{value: "0%", total: 3, expected: 0},
+		{value: "50%", total: 0, expected: 0},
+		{value: "", total: 3, err: true},
+		{value: "150%", total: 3, err: true},
+		{value: "-1", total: 3, err: true},
+		{value: "two", total: 3, err: true},
+	}
+
+	for _, item := range table {
+		required, err := Required(item.value, item.total)
+		if item.err {
+			assert.Error(t, err, item.value)
+			continue
+		}
+
+		assert.NoError(t, err, item.value)
+		assert.Equal(t, item.expected, required, item.value)
+	}
+}
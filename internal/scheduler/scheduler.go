@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Scheduler evaluates jobs in the background, each on its own interval, and keeps
+// the latest result of every job.
+type Scheduler struct {
+	logger      *zap.Logger
+	idleTimeout time.Duration
+	maxJobs     int
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	done chan struct{}
+	once sync.Once
+}
+
+type job struct {
+	value      interface{}
+	updated    time.Time
+	lastAccess time.Time
+}
+
+// New creates a new scheduler. Jobs that haven't been read for longer than
+// idleTimeout are stopped; zero means that jobs are never stopped. At most maxJobs
+// jobs are scheduled at once; zero means no limit.
+func New(logger *zap.Logger, idleTimeout time.Duration, maxJobs int) *Scheduler {
+	return &Scheduler{
+		logger:      logger,
+		idleTimeout: idleTimeout,
+		maxJobs:     maxJobs,
+		jobs:        map[string]*job{},
+		done:        make(chan struct{}),
+	}
+}
+
+// Get returns the latest result of the job identified by key along with the time
+// when it was evaluated. If the job doesn't exist yet, fn is evaluated
+// synchronously and then scheduled to be re-evaluated every interval. If the limit
+// of jobs is reached, fn is evaluated on every call instead.
+func (s *Scheduler) Get(key string, interval time.Duration, fn func() interface{}) (interface{}, time.Time) {
+	s.mu.Lock()
+	if j, ok := s.jobs[key]; ok {
+		j.lastAccess = time.Now()
+		value, updated := j.value, j.updated
+		s.mu.Unlock()
+
+		return value, updated
+	}
+	s.mu.Unlock()
+
+	value := fn()
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another request could have scheduled the job in the meantime.
+	if j, ok := s.jobs[key]; ok {
+		j.lastAccess = now
+		return j.value, j.updated
+	}
+
+	if s.maxJobs != 0 && len(s.jobs) >= s.maxJobs {
+		s.logger.Debug("limit of jobs has been reached",
+			zap.String("key", key),
+			zap.Int("max_jobs", s.maxJobs),
+		)
+
+		return value, now
+	}
+
+	s.jobs[key] = &job{
+		value:      value,
+		updated:    now,
+		lastAccess: now,
+	}
+
+	s.logger.Debug("schedule job",
+		zap.String("key", key),
+		zap.Duration("interval", interval),
+	)
+
+	go s.run(key, interval, fn)
+
+	return value, now
+}
+
+// Stop stops all jobs.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *Scheduler) run(key string, interval time.Duration, fn func() interface{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		if s.idle(key) {
+			s.logger.Debug("remove idle job", zap.String("key", key))
+			return
+		}
+
+		value := fn()
+
+		s.mu.Lock()
+		if j, ok := s.jobs[key]; ok {
+			j.value = value
+			j.updated = time.Now()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// idle removes the job if it hasn't been read for longer than the idle timeout.
+func (s *Scheduler) idle(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[key]
+	if !ok {
+		return true
+	}
+
+	if s.idleTimeout != 0 && time.Since(j.lastAccess) > s.idleTimeout {
+		delete(s.jobs, key)
+		return true
+	}
+
+	return false
+}
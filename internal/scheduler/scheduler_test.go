@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/log"
+)
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	logger, _ := log.NewAtLevel("ERROR")
+	s := New(logger, 0, 0)
+	defer s.Stop()
+
+	var calls int32
+	fn := func() interface{} {
+		return atomic.AddInt32(&calls, 1)
+	}
+
+	value, updated := s.Get("job", 10*time.Millisecond, fn)
+	assert.Equal(t, int32(1), value)
+	assert.WithinDuration(t, time.Now(), updated, time.Second)
+
+	// The job is evaluated in the background.
+	assert.Eventually(t, func() bool {
+		value, _ := s.Get("job", 10*time.Millisecond, fn)
+		return value.(int32) > 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	logger, _ := log.NewAtLevel("ERROR")
+	s := New(logger, 20*time.Millisecond, 0)
+	defer s.Stop()
+
+	s.Get("job", 5*time.Millisecond, func() interface{} {
+		return true
+	})
+
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		_, ok := s.jobs["job"]
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestMaxJobs(t *testing.T) {
+	t.Parallel()
+
+	logger, _ := log.NewAtLevel("ERROR")
+	s := New(logger, 0, 1)
+	defer s.Stop()
+
+	var calls int32
+	fn := func() interface{} {
+		return atomic.AddInt32(&calls, 1)
+	}
+
+	s.Get("job", time.Hour, fn)
+
+	// Jobs over the limit aren't scheduled, they're evaluated on every call.
+	value, _ := s.Get("other", time.Hour, fn)
+	assert.Equal(t, int32(2), value)
+	value, _ = s.Get("other", time.Hour, fn)
+	assert.Equal(t, int32(3), value)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Len(t, s.jobs, 1)
+}
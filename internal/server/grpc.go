@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	handler "github.com/tczekajlo/healthgroup/internal/handlers"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GRPC serves the grpc.health.v1.Health service. The service name of a request
+// encodes the discovery target, e.g. kubernetes/default/api or consul/redis, and
+// it's evaluated by the same handlers as the /health/* HTTP endpoints.
+type GRPC struct {
+	server *grpc.Server
+	health *healthServer
+	addr   string
+}
+
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	logger        *zap.Logger
+	handler       fasthttp.RequestHandler
+	watchInterval time.Duration
+	done          chan struct{}
+}
+
+// defaultWatchInterval is used when the configured watch interval isn't positive.
+const defaultWatchInterval = time.Second * 5
+
+// NewGRPC creates a gRPC server that answers health check requests using the handler of the HTTP app.
+func NewGRPC(config *config.Config, logger *zap.Logger, handler fasthttp.RequestHandler) *GRPC {
+	health := &healthServer{
+		logger:        logger,
+		handler:       handler,
+		watchInterval: config.GRPC.WatchInterval,
+		done:          make(chan struct{}),
+	}
+
+	if health.watchInterval <= 0 {
+		health.watchInterval = defaultWatchInterval
+	}
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health)
+
+	return &GRPC{
+		server: server,
+		health: health,
+		addr:   fmt.Sprintf("%s:%d", config.GRPC.Address, config.GRPC.Port),
+	}
+}
+
+// Listen serves gRPC requests on the configured address.
+func (g *GRPC) Listen() error {
+	ln, err := net.Listen("tcp", g.addr)
+	if err != nil {
+		return err
+	}
+
+	return g.server.Serve(ln)
+}
+
+// Shutdown stops all watchers and gracefully stops the server.
+func (g *GRPC) Shutdown() {
+	close(g.health.done)
+	g.server.GracefulStop()
+}
+
+func (s *healthServer) Check(_ context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st := s.status(req.GetService())
+	if st == healthpb.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Errorf(codes.NotFound, "unknown service: %s", req.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+
+	for {
+		if st := s.status(req.GetService()); st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-ticker.C:
+		}
+	}
+}
+
+// status evaluates the service by dispatching a request to the /health/<service> route.
+// An empty service name refers to the overall health of healthgroup itself.
+func (s *healthServer) status(service string) healthpb.HealthCheckResponse_ServingStatus {
+	if service == "" {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fiber.MethodGet)
+	ctx.Request.Header.SetUserAgent("grpc-health")
+	ctx.Request.SetRequestURI("/health/" + strings.TrimPrefix(service, "/"))
+
+	s.handler(ctx)
+
+	code := ctx.Response.StatusCode()
+
+	s.logger.Debug("gRPC health check",
+		zap.String("service", service),
+		zap.Int("status", code),
+	)
+
+	// A degraded service is still serving, whatever status code is configured for it.
+	switch {
+	case code == fiber.StatusNotFound:
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+	case code == fiber.StatusOK, string(ctx.Response.Header.Peek(handler.HeaderHealthStatus)) == handler.HealthStatusWarn:
+		return healthpb.HealthCheckResponse_SERVING
+	default:
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCHealth(t *testing.T) {
+	logger, _ := log.NewAtLevel("ERROR")
+
+	c := config.New(config.WithLogger(logger))
+	errSetDefault := c.SetDefault()
+	assert.Empty(t, errSetDefault)
+
+	c.GRPC.Address = "127.0.0.1"
+	c.GRPC.Port = 0
+	c.GRPC.WatchInterval = 10 * time.Millisecond
+
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+	app.Get("/health/kubernetes/:namespace/:service", func(c *fiber.Ctx) error {
+		if c.Params("service") == "api" {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		return c.SendStatus(fiber.StatusServiceUnavailable)
+	})
+	app.Get("/health/consul/:service", func(c *fiber.Ctx) error {
+		if c.Query("tag") == "primary" {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		return c.SendStatus(fiber.StatusNotFound)
+	})
+
+	server := NewGRPC(c, logger, app.Handler())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = server.server.Serve(ln)
+	}()
+	t.Cleanup(server.Shutdown)
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	client := healthpb.NewHealthClient(conn)
+
+	table := []struct {
+		desc     string
+		service  string
+		expected healthpb.HealthCheckResponse_ServingStatus
+		code     codes.Code
+	}{
+		{
+			desc:     "server",
+			service:  "",
+			expected: healthpb.HealthCheckResponse_SERVING,
+			code:     codes.OK,
+		},
+		{
+			desc:     "Kubernetes service healthy",
+			service:  "kubernetes/default/api",
+			expected: healthpb.HealthCheckResponse_SERVING,
+			code:     codes.OK,
+		},
+		{
+			desc:     "Kubernetes service not healthy",
+			service:  "kubernetes/default/web",
+			expected: healthpb.HealthCheckResponse_NOT_SERVING,
+			code:     codes.OK,
+		},
+		{
+			desc:     "Consul service with a tag",
+			service:  "consul/redis?tag=primary",
+			expected: healthpb.HealthCheckResponse_SERVING,
+			code:     codes.OK,
+		},
+		{
+			desc:    "Consul service not found",
+			service: "consul/redis",
+			code:    codes.NotFound,
+		},
+		{
+			desc:    "unknown route",
+			service: "unknown/service",
+			code:    codes.NotFound,
+		},
+	}
+
+	for _, item := range table {
+		t.Run(item.desc, func(t *testing.T) {
+			resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: item.service})
+			assert.Equal(t, item.code, status.Code(err))
+			assert.Equal(t, item.expected, resp.GetStatus())
+		})
+	}
+
+	t.Run("watch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "consul/redis"})
+		assert.Empty(t, err)
+
+		resp, err := stream.Recv()
+		assert.Empty(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVICE_UNKNOWN, resp.GetStatus())
+
+		cancel()
+		_, err = stream.Recv()
+		assert.NotErrorIs(t, err, io.EOF)
+	})
+}
@@ -10,8 +10,13 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/tczekajlo/healthgroup/internal/config"
+	"github.com/tczekajlo/healthgroup/internal/discovery/k8s"
 	handler "github.com/tczekajlo/healthgroup/internal/handlers"
+	"github.com/tczekajlo/healthgroup/internal/hysteresis"
+	"github.com/tczekajlo/healthgroup/internal/metrics"
 	flogger "github.com/tczekajlo/healthgroup/internal/middleware/logger"
+	fmetrics "github.com/tczekajlo/healthgroup/internal/middleware/metrics"
+	"github.com/tczekajlo/healthgroup/internal/scheduler"
 	"github.com/tczekajlo/healthgroup/internal/version"
 	"go.uber.org/zap"
 )
@@ -28,13 +33,58 @@ func NewHTTP(config *config.Config, logger *zap.Logger) error {
 	app.Use(recover.New())
 	app.Use(requestid.New())
 	app.Use(flogger.New(flogger.Config{
+		Next:   handler.IsInternal,
 		Logger: logger,
 	}))
 
+	var m *metrics.Metrics
+	if config.Metrics.Enabled {
+		m = metrics.New()
+
+		app.Use(fmetrics.New(fmetrics.Config{
+			Next:    handler.IsInternal,
+			Metrics: m,
+		}))
+		app.Get(config.Metrics.Path, m.Handler())
+	}
+
+	var cache *handler.Cache
+	if config.Scheduler.Enabled {
+		s := scheduler.New(logger, config.Scheduler.IdleTimeout, config.Scheduler.MaxJobs)
+		defer s.Stop()
+
+		cache = handler.NewCache(s, config.Scheduler.Interval)
+	}
+
+	opts := []handler.Option{
+		handler.WithMetrics(m),
+		handler.WithCache(cache),
+		handler.WithTracker(hysteresis.New()),
+		handler.WithKubernetes(k8s.NewClients(logger, config, m)),
+	}
+
 	// Routes
-	app.Get("/health/kubernetes/:namespace/:service", handler.HealthKubernetes(config, logger))
-	app.Get("/health/consul/:namespace/:service", handler.HealthConsul(config, logger))
-	app.Get("/health/consul/:service", handler.HealthConsul(config, logger))
+	app.Get("/health/kubernetes/:namespace/:service", handler.HealthKubernetes(config, logger, opts...))
+	for _, kind := range []string{k8s.KindDeployment, k8s.KindStatefulSet, k8s.KindDaemonSet} {
+		app.Get("/health/kubernetes/:namespace/"+kind+"/:name", handler.HealthKubernetesWorkload(kind, config, logger, opts...))
+	}
+	for _, kind := range []string{k8s.KindIngress, k8s.KindHTTPRoute} {
+		app.Get("/health/kubernetes/:namespace/"+kind+"/:name", handler.HealthKubernetesRoute(kind, config, logger, opts...))
+	}
+	// Clusters have their own prefix, as /health/kubernetes/:cluster/:namespace/:service
+	// would be ambiguous with the workload and route endpoints.
+	if len(config.Kubernetes.Clusters) > 0 {
+		app.Get("/health/kubernetes-cluster/:cluster/:namespace/:service", handler.HealthKubernetesCluster(config, logger, opts...))
+	}
+	app.Get("/health/kubernetes-selector/:namespace", handler.HealthKubernetesSelector(config, logger, opts...))
+	app.Get("/health/consul/:namespace/:service", handler.HealthConsul(config, logger, opts...))
+	app.Get("/health/consul/:service", handler.HealthConsul(config, logger, opts...))
+	app.Get("/health/consul-query/:query", handler.HealthConsulQuery(config, logger, opts...))
+
+	// The handler is used to evaluate requests in-process, it has to be built
+	// after all routes are registered.
+	appHandler := app.Handler()
+	cache.SetHandler(appHandler)
 
 	logger.Info("Listen", zap.String("addr", addr))
 
@@ -44,11 +94,28 @@ func NewHTTP(config *config.Config, logger *zap.Logger) error {
 		}
 	}()
 
+	var grpcServer *GRPC
+	if config.GRPC.Enabled {
+		grpcServer = NewGRPC(config, logger, appHandler)
+
+		logger.Info("Listen gRPC", zap.String("addr", grpcServer.addr))
+
+		go func() {
+			if err := grpcServer.Listen(); err != nil {
+				panic(err)
+			}
+		}()
+	}
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	<-c
 	logger.Info("Gracefully shutting down...")
+	if grpcServer != nil {
+		grpcServer.Shutdown()
+	}
+
 	if err := app.Shutdown(); err != nil {
 		return err
 	}